@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+)
+
+// anyTargetChanged records whether the most recent runDryRun found any
+// target whose generated output would differ from what's on disk, so
+// main's -check flag can turn that into a non-zero exit.
+var anyTargetChanged bool
+
+// runDryRun runs the generator pipeline exactly like run, but against the
+// in-memory overlay in e.fs: nothing on real disk is touched, and each
+// target prints either "unchanged" or a unified diff of its existing
+// contents against what the generator would write instead. It does not
+// reset anyTargetChanged itself, since --profile all calls it once per
+// profile through runProfiles and a change in an earlier profile must
+// still be visible to the final -check exit code.
+func runDryRun(e env, dataPath string, paths targetPaths, selected []string, profile string) error {
+	return forEachTarget(e, dataPath, paths, selected, profile, diffTarget)
+}
+
+// diffTarget builds t's output for cfg and, if it differs from path's
+// current contents on fs, prints a unified diff instead of writing
+// anything; otherwise it reports the target as unchanged.
+func diffTarget(fs afero.Fs, t Target, cfg config, path string) error {
+	out, err := buildTargetOutput(fs, t, cfg, path)
+	if err != nil {
+		return err
+	}
+	if out.skipped != "" {
+		logSkip("skipping %s: %s", out.name, out.skipped)
+		return nil
+	}
+	if string(out.patched) == string(out.existing) {
+		fmt.Printf("%s: unchanged\n", t.Name())
+		return nil
+	}
+	anyTargetChanged = true
+
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(out.existing)),
+		B:        difflib.SplitLines(string(out.patched)),
+		FromFile: path,
+		ToFile:   path,
+		Context:  3,
+	}
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return fmt.Errorf("diff %s: %w", t.Name(), err)
+	}
+	fmt.Fprintf(os.Stdout, "--- %s ---\n%s", t.Name(), text)
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestDiffTarget_SetsAnyTargetChanged(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	anyTargetChanged = false
+
+	if err := diffTarget(fs, continueTarget{}, config{Bash: bashConfig{Allow: []string{"git"}}}, "/nonexistent/config.json"); err != nil {
+		t.Fatalf("diffTarget() error = %v", err)
+	}
+	if anyTargetChanged {
+		t.Fatal("anyTargetChanged = true after a skipped target, want false")
+	}
+
+	if err := afero.WriteFile(fs, "/config.json", []byte(`{"allowedCommands":["old"]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := diffTarget(fs, continueTarget{}, config{Bash: bashConfig{Allow: []string{"git"}}}, "/config.json"); err != nil {
+		t.Fatalf("diffTarget() error = %v", err)
+	}
+	if !anyTargetChanged {
+		t.Fatal("anyTargetChanged = false after a changed target, want true")
+	}
+}
+
+// TestRunDryRun_AccumulatesAcrossProfiles guards against --profile all
+// losing an earlier profile's diff: runProfiles calls runDryRun once per
+// profile, so a later no-op profile must not erase an earlier profile's
+// "changed" result.
+func TestRunDryRun_AccumulatesAcrossProfiles(t *testing.T) {
+	root, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd() error = %v", err)
+	}
+
+	unchanged := "#!/bin/sh\n# Generated by tools/permissions-gen. Do not edit by hand.\n\n" +
+		"export ALLOWED_BASH_PREFIXES=''\nexport ASK_BASH_PREFIXES=''\nexport DENIED_BASH_PREFIXES=''\n"
+
+	fs := afero.NewMemMapFs()
+	dataPath := filepath.Join(root, defaultDataPath)
+	if err := fs.MkdirAll(filepath.Dir(dataPath), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	yaml := "profiles:\n" +
+		"  aaa:\n" +
+		"    bash:\n" +
+		"      allow: [curl]\n" +
+		"    paths:\n" +
+		"      bashenv: dot_local/bin/aaa.sh\n" +
+		"  zzz:\n" +
+		"    paths:\n" +
+		"      bashenv: dot_local/bin/zzz.sh\n"
+	if err := afero.WriteFile(fs, dataPath, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := afero.WriteFile(fs, filepath.Join(root, "dot_local/bin/zzz.sh"), []byte(unchanged), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	e := env{fs: fs}
+	selected := []string{"bashenv"}
+
+	anyTargetChanged = false
+	if err := runProfiles(e, "", nil, selected, "all", runDryRun); err != nil {
+		t.Fatalf("runProfiles() error = %v", err)
+	}
+	if !anyTargetChanged {
+		t.Fatal("anyTargetChanged = false after --profile all with an earlier changed profile, want true")
+	}
+}
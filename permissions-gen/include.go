@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// httpGet fetches url's body. It's a package var, not a direct net/http
+// call, so tests can swap in a fake without a real network round-trip.
+var httpGet = func(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// resolveIncludes fetches each of cfg.Includes (a local path or an
+// https:// URL) as a permissions.yaml fragment and merges it into cfg in
+// order, so a later include's deny entries can still veto an earlier
+// include's (or the base config's) allow entries. A local ref resolves
+// relative to root, the same as target and policy paths, so it works the
+// same whether permissions-gen runs from the repo root or a subdirectory.
+func resolveIncludes(e env, root string, cfg config) (config, error) {
+	for _, ref := range cfg.Includes {
+		data, err := loadInclude(e, root, ref)
+		if err != nil {
+			return config{}, fmt.Errorf("include %s: %w", ref, err)
+		}
+
+		var fragment config
+		if err := yaml.Unmarshal(data, &fragment); err != nil {
+			return config{}, fmt.Errorf("include %s: parse yaml: %w", ref, err)
+		}
+
+		cfg = mergeConfig(cfg, fragment)
+	}
+	return cfg, nil
+}
+
+func loadInclude(e env, root, ref string) ([]byte, error) {
+	if isRemoteRef(ref) {
+		return fetchCached(e, ref)
+	}
+	path, err := resolveRelative(e, root, ref)
+	if err != nil {
+		return nil, err
+	}
+	return afero.ReadFile(e.fs, path)
+}
+
+func isRemoteRef(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")
+}
+
+// fetchCached serves url out of ~/.cache/permissions-gen/<sha256(url)>.yaml
+// when present, otherwise fetches it and populates that cache entry.
+func fetchCached(e env, url string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(url))
+	cacheDir := filepath.Join(e.home, ".cache", "permissions-gen")
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".yaml")
+
+	if data, err := afero.ReadFile(e.fs, cachePath); err == nil {
+		return data, nil
+	}
+
+	data, err := httpGet(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	if err := e.fs.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	if err := afero.WriteFile(e.fs, cachePath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write cache: %w", err)
+	}
+	return data, nil
+}
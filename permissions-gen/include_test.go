@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestResolveIncludes_LocalPath(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/bundle.yaml", []byte("bash:\n  allow: [ls]\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	e := env{fs: fs, home: "/home/test"}
+
+	cfg := config{
+		Bash:     bashConfig{Allow: []string{"git"}},
+		Includes: []string{"/bundle.yaml"},
+	}
+
+	got, err := resolveIncludes(e, "/repo", cfg)
+	if err != nil {
+		t.Fatalf("resolveIncludes() error = %v", err)
+	}
+	want := []string{"git", "ls"}
+	if len(got.Bash.Allow) != 2 || got.Bash.Allow[0] != want[0] || got.Bash.Allow[1] != want[1] {
+		t.Fatalf("resolveIncludes().Bash.Allow = %#v, want %#v", got.Bash.Allow, want)
+	}
+}
+
+func TestResolveIncludes_RelativePathResolvesAgainstRoot(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/shared/extra.yaml", []byte("bash:\n  allow: [ls]\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	e := env{fs: fs, home: "/home/test"}
+
+	cfg := config{
+		Bash:     bashConfig{Allow: []string{"git"}},
+		Includes: []string{"shared/extra.yaml"},
+	}
+
+	got, err := resolveIncludes(e, "/repo", cfg)
+	if err != nil {
+		t.Fatalf("resolveIncludes() error = %v", err)
+	}
+	want := []string{"git", "ls"}
+	if len(got.Bash.Allow) != 2 || got.Bash.Allow[0] != want[0] || got.Bash.Allow[1] != want[1] {
+		t.Fatalf("resolveIncludes().Bash.Allow = %#v, want %#v", got.Bash.Allow, want)
+	}
+}
+
+func TestFetchCached_PopulatesCacheOnce(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := env{fs: fs, home: "/home/test"}
+
+	calls := 0
+	orig := httpGet
+	httpGet = func(url string) ([]byte, error) {
+		calls++
+		return []byte("bash:\n  allow: [ls]\n"), nil
+	}
+	defer func() { httpGet = orig }()
+
+	if _, err := fetchCached(e, "https://example.com/bundle.yaml"); err != nil {
+		t.Fatalf("fetchCached() error = %v", err)
+	}
+	if _, err := fetchCached(e, "https://example.com/bundle.yaml"); err != nil {
+		t.Fatalf("fetchCached() error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("httpGet called %d times, want 1 (second call should hit cache)", calls)
+	}
+}
+
+func TestFetchCached_PropagatesFetchError(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := env{fs: fs, home: "/home/test"}
+
+	orig := httpGet
+	httpGet = func(url string) ([]byte, error) { return nil, fmt.Errorf("boom") }
+	defer func() { httpGet = orig }()
+
+	if _, err := fetchCached(e, "https://example.com/missing.yaml"); err == nil {
+		t.Fatal("fetchCached() expected error")
+	}
+}
@@ -0,0 +1,256 @@
+// Package jsonedit parses JSONC (JSON with "//"/"/* */" comments and
+// trailing commas) into a position-preserving tree and lets callers
+// surgically replace individual values, round-tripping everything else
+// byte-for-byte.
+package jsonedit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Kind identifies the JSON value kind a Node holds.
+type Kind int
+
+const (
+	KindObject Kind = iota
+	KindArray
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+)
+
+// Member is a single "key": value pair of an object Node.
+type Member struct {
+	Key   *Node
+	Value *Node
+}
+
+// Node is a JSON value together with the byte range ([Start, End],
+// inclusive) it occupies in the original source.
+type Node struct {
+	Kind     Kind
+	Start    int
+	End      int
+	Members  []*Member // populated when Kind == KindObject
+	Elements []*Node   // populated when Kind == KindArray
+
+	// KeyStart is the byte offset of the opening quote of the object key
+	// that this Node is the value of, or -1 for the root value and array
+	// elements. It lets callers recover the indentation of the line the
+	// key was declared on, which usually differs from Start for
+	// multi-line values (`"key": {` puts Start after the key).
+	KeyStart int
+
+	doc *Doc
+}
+
+type edit struct {
+	start, end int
+	raw        []byte
+}
+
+// Doc holds the parsed tree plus any pending edits against its source.
+type Doc struct {
+	src   []byte
+	root  *Node
+	edits []edit
+}
+
+// Parse parses JSONC bytes into a Doc.
+func Parse(data []byte) (*Doc, error) {
+	toks, err := lex(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Doc{src: data}
+	pos := 0
+	root, err := parseValue(toks, &pos, doc)
+	if err != nil {
+		return nil, err
+	}
+	skipTrivia(toks, &pos)
+	if toks[pos].kind != tokEOF {
+		return nil, fmt.Errorf("unexpected trailing content at byte %d", toks[pos].start)
+	}
+
+	doc.root = root
+	return doc, nil
+}
+
+// Find walks path through nested objects, starting at the root, and
+// returns the Node found at the end of it.
+func (d *Doc) Find(path ...string) (*Node, bool) {
+	cur := d.root
+	for _, key := range path {
+		if cur == nil || cur.Kind != KindObject {
+			return nil, false
+		}
+		member := cur.member(key)
+		if member == nil {
+			return nil, false
+		}
+		cur = member.Value
+	}
+	return cur, cur != nil
+}
+
+// Bytes renders the document, splicing in every Replace'd span over the
+// original source. Edited spans must not overlap.
+func (d *Doc) Bytes() ([]byte, error) {
+	edits := append([]edit(nil), d.edits...)
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var out []byte
+	pos := 0
+	for _, e := range edits {
+		if e.start < pos {
+			return nil, fmt.Errorf("overlapping edits at byte %d", e.start)
+		}
+		out = append(out, d.src[pos:e.start]...)
+		out = append(out, e.raw...)
+		pos = e.end + 1
+	}
+	out = append(out, d.src[pos:]...)
+	return out, nil
+}
+
+// Replace marks raw as the new contents of n's byte span. The
+// replacement is only materialized once the owning Doc's Bytes is
+// called.
+func (n *Node) Replace(raw []byte) {
+	n.doc.edits = append(n.doc.edits, edit{start: n.Start, end: n.End, raw: append([]byte(nil), raw...)})
+}
+
+// String decodes a KindString node's value.
+func (n *Node) String() (string, error) {
+	if n.Kind != KindString {
+		return "", fmt.Errorf("node is not a string")
+	}
+	var s string
+	if err := json.Unmarshal(n.doc.src[n.Start:n.End+1], &s); err != nil {
+		return "", fmt.Errorf("decode string: %w", err)
+	}
+	return s, nil
+}
+
+func (n *Node) member(key string) *Member {
+	for _, m := range n.Members {
+		decoded, err := m.Key.String()
+		if err == nil && decoded == key {
+			return m
+		}
+	}
+	return nil
+}
+
+func skipTrivia(toks []token, pos *int) {
+	for toks[*pos].kind == tokComment {
+		*pos++
+	}
+}
+
+func parseValue(toks []token, pos *int, doc *Doc) (*Node, error) {
+	skipTrivia(toks, pos)
+	tok := toks[*pos]
+
+	switch tok.kind {
+	case tokLBrace:
+		return parseObject(toks, pos, doc)
+	case tokLBracket:
+		return parseArray(toks, pos, doc)
+	case tokString:
+		*pos++
+		return &Node{Kind: KindString, Start: tok.start, End: tok.end - 1, KeyStart: -1, doc: doc}, nil
+	case tokNumber:
+		*pos++
+		return &Node{Kind: KindNumber, Start: tok.start, End: tok.end - 1, KeyStart: -1, doc: doc}, nil
+	case tokTrue, tokFalse:
+		*pos++
+		return &Node{Kind: KindBool, Start: tok.start, End: tok.end - 1, KeyStart: -1, doc: doc}, nil
+	case tokNull:
+		*pos++
+		return &Node{Kind: KindNull, Start: tok.start, End: tok.end - 1, KeyStart: -1, doc: doc}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token at byte %d", tok.start)
+	}
+}
+
+func parseObject(toks []token, pos *int, doc *Doc) (*Node, error) {
+	start := toks[*pos].start
+	*pos++ // consume '{'
+
+	obj := &Node{Kind: KindObject, Start: start, KeyStart: -1, doc: doc}
+	for {
+		skipTrivia(toks, pos)
+		if toks[*pos].kind == tokRBrace {
+			obj.End = toks[*pos].start
+			*pos++
+			return obj, nil
+		}
+
+		keyTok := toks[*pos]
+		if keyTok.kind != tokString {
+			return nil, fmt.Errorf("expected object key at byte %d", keyTok.start)
+		}
+		key := &Node{Kind: KindString, Start: keyTok.start, End: keyTok.end - 1, KeyStart: -1, doc: doc}
+		*pos++
+
+		skipTrivia(toks, pos)
+		if toks[*pos].kind != tokColon {
+			return nil, fmt.Errorf("expected ':' at byte %d", toks[*pos].start)
+		}
+		*pos++
+
+		value, err := parseValue(toks, pos, doc)
+		if err != nil {
+			return nil, err
+		}
+		value.KeyStart = key.Start
+		obj.Members = append(obj.Members, &Member{Key: key, Value: value})
+
+		skipTrivia(toks, pos)
+		switch toks[*pos].kind {
+		case tokComma:
+			*pos++
+		case tokRBrace:
+			// trailing comma omitted; loop closes the object next iteration
+		default:
+			return nil, fmt.Errorf("expected ',' or '}' at byte %d", toks[*pos].start)
+		}
+	}
+}
+
+func parseArray(toks []token, pos *int, doc *Doc) (*Node, error) {
+	start := toks[*pos].start
+	*pos++ // consume '['
+
+	arr := &Node{Kind: KindArray, Start: start, KeyStart: -1, doc: doc}
+	for {
+		skipTrivia(toks, pos)
+		if toks[*pos].kind == tokRBracket {
+			arr.End = toks[*pos].start
+			*pos++
+			return arr, nil
+		}
+
+		elem, err := parseValue(toks, pos, doc)
+		if err != nil {
+			return nil, err
+		}
+		arr.Elements = append(arr.Elements, elem)
+
+		skipTrivia(toks, pos)
+		switch toks[*pos].kind {
+		case tokComma:
+			*pos++
+		case tokRBracket:
+			// trailing comma omitted; loop closes the array next iteration
+		default:
+			return nil, fmt.Errorf("expected ',' or ']' at byte %d", toks[*pos].start)
+		}
+	}
+}
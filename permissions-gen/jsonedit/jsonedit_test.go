@@ -0,0 +1,86 @@
+package jsonedit
+
+import "testing"
+
+func TestParse_RoundTrip(t *testing.T) {
+	src := `{"a": 1, "b": [1, 2, 3]}`
+	doc, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if string(got) != src {
+		t.Fatalf("Bytes() = %q, want %q", got, src)
+	}
+}
+
+func TestParse_CommentsAndTrailingCommas(t *testing.T) {
+	src := "{\n  // a comment\n  \"a\": 1,\n  \"b\": [1, 2,],\n}\n"
+	doc, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	node, ok := doc.Find("b")
+	if !ok {
+		t.Fatal("Find(b) = false, want true")
+	}
+	if node.Kind != KindArray || len(node.Elements) != 2 {
+		t.Fatalf("Find(b) = %#v, want a 2-element array", node)
+	}
+}
+
+func TestDoc_Find_Nested(t *testing.T) {
+	doc, err := Parse([]byte(`{"a": {"b": {"c": "value"}}}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	node, ok := doc.Find("a", "b", "c")
+	if !ok {
+		t.Fatal("Find(a, b, c) = false, want true")
+	}
+	got, err := node.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	if got != "value" {
+		t.Fatalf("String() = %q, want %q", got, "value")
+	}
+
+	if _, ok := doc.Find("a", "missing"); ok {
+		t.Fatal("Find(a, missing) = true, want false")
+	}
+}
+
+func TestNode_Replace(t *testing.T) {
+	doc, err := Parse([]byte(`{"permissions": {"allow": []}, "other": true}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	node, ok := doc.Find("permissions")
+	if !ok {
+		t.Fatal("Find(permissions) = false, want true")
+	}
+	node.Replace([]byte(`{"allow": ["a"]}`))
+
+	got, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	want := `{"permissions": {"allow": ["a"]}, "other": true}`
+	if string(got) != want {
+		t.Fatalf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestParse_UnterminatedString(t *testing.T) {
+	_, err := Parse([]byte(`{"a": "unterminated`))
+	if err == nil {
+		t.Fatal("Parse() expected error for unterminated string")
+	}
+}
@@ -0,0 +1,153 @@
+package jsonedit
+
+import "fmt"
+
+type tokenKind int
+
+const (
+	tokLBrace tokenKind = iota
+	tokRBrace
+	tokLBracket
+	tokRBracket
+	tokColon
+	tokComma
+	tokString
+	tokNumber
+	tokTrue
+	tokFalse
+	tokNull
+	tokComment
+	tokEOF
+)
+
+// token is a lexed unit with byte offsets into the original source.
+// end is exclusive.
+type token struct {
+	kind       tokenKind
+	start, end int
+}
+
+// lex tokenizes JSONC: plain JSON plus "//" and "/* */" comments and
+// trailing commas (the latter are accepted by the parser, not the lexer).
+func lex(data []byte) ([]token, error) {
+	var toks []token
+	i, n := 0, len(data)
+
+	for i < n {
+		c := data[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '/' && i+1 < n && data[i+1] == '/':
+			start := i
+			for i < n && data[i] != '\n' {
+				i++
+			}
+			toks = append(toks, token{tokComment, start, i})
+		case c == '/' && i+1 < n && data[i+1] == '*':
+			start := i
+			i += 2
+			for i+1 < n && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			if i+1 >= n {
+				return nil, fmt.Errorf("unterminated comment at byte %d", start)
+			}
+			i += 2
+			toks = append(toks, token{tokComment, start, i})
+		case c == '{':
+			toks = append(toks, token{tokLBrace, i, i + 1})
+			i++
+		case c == '}':
+			toks = append(toks, token{tokRBrace, i, i + 1})
+			i++
+		case c == '[':
+			toks = append(toks, token{tokLBracket, i, i + 1})
+			i++
+		case c == ']':
+			toks = append(toks, token{tokRBracket, i, i + 1})
+			i++
+		case c == ':':
+			toks = append(toks, token{tokColon, i, i + 1})
+			i++
+		case c == ',':
+			toks = append(toks, token{tokComma, i, i + 1})
+			i++
+		case c == '"':
+			end, err := lexString(data, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokString, i, end})
+			i = end
+		case c == '-' || isDigit(c):
+			end := lexNumber(data, i)
+			toks = append(toks, token{tokNumber, i, end})
+			i = end
+		case matchLiteral(data, i, "true"):
+			toks = append(toks, token{tokTrue, i, i + 4})
+			i += 4
+		case matchLiteral(data, i, "false"):
+			toks = append(toks, token{tokFalse, i, i + 5})
+			i += 5
+		case matchLiteral(data, i, "null"):
+			toks = append(toks, token{tokNull, i, i + 4})
+			i += 4
+		default:
+			return nil, fmt.Errorf("unexpected byte %q at %d", c, i)
+		}
+	}
+
+	toks = append(toks, token{tokEOF, n, n})
+	return toks, nil
+}
+
+func lexString(data []byte, start int) (int, error) {
+	i, n := start+1, len(data)
+	for i < n {
+		switch data[i] {
+		case '\\':
+			i += 2
+		case '"':
+			return i + 1, nil
+		default:
+			i++
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at byte %d", start)
+}
+
+func lexNumber(data []byte, start int) int {
+	i, n := start, len(data)
+	if data[i] == '-' {
+		i++
+	}
+	for i < n && isDigit(data[i]) {
+		i++
+	}
+	if i < n && data[i] == '.' {
+		i++
+		for i < n && isDigit(data[i]) {
+			i++
+		}
+	}
+	if i < n && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < n && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		for i < n && isDigit(data[i]) {
+			i++
+		}
+	}
+	return i
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+func matchLiteral(data []byte, pos int, lit string) bool {
+	if pos+len(lit) > len(data) {
+		return false
+	}
+	return string(data[pos:pos+len(lit)]) == lit
+}
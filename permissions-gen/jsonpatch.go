@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// replaceBlockWithLines re-indents lines to match the marker's own
+// indentation and splices them between the PERMISSIONS markers at
+// [start, end) in contents.
+func replaceBlockWithLines(contents string, start, end int, lines []string) (string, error) {
+	indent, err := lineIndent(contents, start)
+	if err != nil {
+		return "", err
+	}
+
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+
+	block := startMarker + "\n" + strings.Join(lines, "\n") + "\n" + indent + endMarker
+
+	return contents[:start] + block + contents[end+len(endMarker):], nil
+}
+
+func lineIndent(contents string, markerPos int) (string, error) {
+	lineStart := strings.LastIndex(contents[:markerPos], "\n") + 1
+	indent := contents[lineStart:markerPos]
+	if strings.TrimSpace(indent) != "" {
+		return "", fmt.Errorf("marker must be on its own line: %q", indent)
+	}
+	return indent, nil
+}
+
+// innerJSONLines strips the outermost braces from a MarshalIndent'd JSON
+// object and dedents the remaining lines by one level, so they can be
+// reindented to whatever the surrounding file needs.
+func innerJSONLines(data string) ([]string, error) {
+	lines := strings.Split(data, "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected json: too few lines")
+	}
+
+	inner := lines[1 : len(lines)-1]
+	for i, line := range inner {
+		if trimmed, ok := strings.CutPrefix(line, "  "); ok {
+			inner[i] = trimmed
+			continue
+		}
+		inner[i] = line
+	}
+
+	return inner, nil
+}
+
+func indentMultilineValue(value, indent string) string {
+	lines := strings.Split(value, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = indent + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineIndentForPos returns the text preceding pos on its line, to use as
+// the indentation for a multi-line replacement at pos. If that text isn't
+// all whitespace (pos isn't the first token on its line, as happens in a
+// compact/minified document) there's no indentation to inherit, so it
+// returns "" rather than prepending non-whitespace to every later line.
+func lineIndentForPos(contents string, pos int) string {
+	lineStart := strings.LastIndex(contents[:pos], "\n") + 1
+	indent := contents[lineStart:pos]
+	if strings.TrimSpace(indent) != "" {
+		return ""
+	}
+	return indent
+}
+
+func jsonString(value string) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineIndent_MarkerNotAlone(t *testing.T) {
+	contents := "  prefix " + startMarker
+	pos := strings.Index(contents, startMarker)
+	if pos == -1 {
+		t.Fatal("start marker not found in test contents")
+	}
+	_, err := lineIndent(contents, pos)
+	if err == nil {
+		t.Fatal("lineIndent() expected error for marker not on its own line")
+	}
+}
+
+func TestInnerJSONLines(t *testing.T) {
+	got, err := innerJSONLines("{\n  \"a\": 1,\n  \"b\": 2\n}")
+	if err != nil {
+		t.Fatalf("innerJSONLines() error = %v", err)
+	}
+	want := []string{"\"a\": 1,", "\"b\": 2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("innerJSONLines() = %#v, want %#v", got, want)
+	}
+}
+
+func TestInnerJSONLines_TooFewLines(t *testing.T) {
+	_, err := innerJSONLines("{}")
+	if err == nil {
+		t.Fatal("innerJSONLines() expected error for single-line json")
+	}
+}
@@ -1,15 +1,16 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
-	"sort"
 	"strings"
 
+	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,16 +18,42 @@ const (
 	startMarker = "{{/* PERMISSIONS:START */}}"
 	endMarker   = "{{/* PERMISSIONS:END */}}"
 
-	defaultDataPath     = ".chezmoidata/permissions.yaml"
-	defaultClaudePath   = "dot_claude/settings.json.tmpl"
-	defaultCodexPath    = "dot_codex/rules/default.rules"
-	defaultOpencodePath = "dot_config/opencode/opencode.json"
+	defaultDataPath = ".chezmoidata/permissions.yaml"
 )
 
+// defaultTargetPaths gives each registered Target's output file a default
+// location relative to the chezmoi source root, used whenever its flag is
+// left empty.
+var defaultTargetPaths = map[string]string{
+	"claude":   "dot_claude/settings.json.tmpl",
+	"codex":    "dot_codex/rules/default.rules",
+	"opencode": "dot_config/opencode/opencode.json",
+	"aider":    "dot_aider.conf.yml",
+	"continue": "dot_continue/config.json",
+	"bashenv":  "dot_local/bin/executable_permissions-bashenv.sh",
+}
+
 type config struct {
 	Bash     bashConfig     `yaml:"bash"`
 	Claude   claudeConfig   `yaml:"claude"`
 	Opencode opencodeConfig `yaml:"opencode"`
+
+	// Policies lists Rego files whose data.dotfiles.permissions.decision
+	// rule classifies the commands in PolicyInputs as allow/ask/deny/skip.
+	Policies     []string      `yaml:"policies"`
+	PolicyInputs []policyInput `yaml:"policyInputs"`
+
+	// Includes lists additional permissions.yaml fragments (local paths or
+	// https:// URLs) merged into this config before any target renders, so
+	// a team can publish a shared bundle instead of every repo hand-copying
+	// entries. See resolveIncludes.
+	Includes []string `yaml:"includes"`
+
+	// Profiles names authorization contexts (e.g. "trusted", "sandbox",
+	// "ci") that overlay the base bash/claude/opencode sections and may
+	// redirect specific targets to their own output paths. See
+	// applyProfileOverlay.
+	Profiles map[string]profileConfig `yaml:"profiles"`
 }
 
 type bashConfig struct {
@@ -42,13 +69,6 @@ type claudeConfig struct {
 	AdditionalDirectories []string `yaml:"additionalDirectories"`
 }
 
-type claudePermissions struct {
-	Allow                 []string `json:"allow"`
-	Ask                   []string `json:"ask"`
-	Deny                  []string `json:"deny"`
-	AdditionalDirectories []string `json:"additionalDirectories"`
-}
-
 type opencodeConfig struct {
 	Bash   opencodeSectionConfig            `yaml:"bash"`
 	Others map[string]opencodeSectionConfig `yaml:",inline"`
@@ -89,19 +109,109 @@ const bashSentinel = "__BASH__"
 
 var quiet bool
 
+// targetPaths holds the resolved (or user-supplied) output path for each
+// registered target, keyed by Target.Name().
+type targetPaths map[string]string
+
+// env bundles the filesystem the generator reads and writes through and the
+// home directory "~" expands to. Threading this instead of calling os
+// directly lets tests and -dry-run swap in an in-memory afero.Fs and a
+// fixed home directory, rather than touching the real disk and $HOME.
+type env struct {
+	fs   afero.Fs
+	home string
+}
+
 func main() {
 	dataPath := flag.String("data", "", "path to permissions YAML")
 	claudePath := flag.String("target", "", "path to settings.json.tmpl")
 	codexPath := flag.String("codex", "", "path to default.rules")
 	opencodePath := flag.String("opencode", "", "path to opencode.json")
+	aiderPath := flag.String("aider", "", "path to .aider.conf.yml")
+	continuePath := flag.String("continue", "", "path to Continue's config.json")
+	bashenvPath := flag.String("bashenv", "", "path to the bash-prefix export script")
+	targets := flag.String("targets", "", "comma-separated list of targets to render (default: all registered targets)")
+	profile := flag.String("profile", "", "named profile to overlay onto the base config, or \"all\" to render every profile")
+	watch := flag.Bool("watch", false, "keep running and regenerate on config/policy changes")
+	dryRun := flag.Bool("dry-run", false, "print a unified diff of what each target would change instead of writing it")
+	diff := flag.Bool("diff", false, "alias for -dry-run")
+	check := flag.Bool("check", false, "with -dry-run/-diff, exit non-zero if any target would change")
 	flag.BoolVar(&quiet, "quiet", false, "suppress skip messages")
 	flag.BoolVar(&quiet, "q", false, "suppress skip messages (shorthand)")
 	flag.Parse()
 
-	if err := run(*dataPath, *claudePath, *codexPath, *opencodePath); err != nil {
+	paths := targetPaths{
+		"claude":   *claudePath,
+		"codex":    *codexPath,
+		"opencode": *opencodePath,
+		"aider":    *aiderPath,
+		"continue": *continuePath,
+		"bashenv":  *bashenvPath,
+	}
+
+	selected, err := parseTargetSelection(*targets)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("resolve home: %w", err))
+		os.Exit(1)
+	}
+	osFS := afero.NewOsFs()
+	e := env{fs: osFS, home: home}
+
+	dryRunEnabled := *dryRun || *diff
+	if *check && !dryRunEnabled {
+		fmt.Fprintln(os.Stderr, "-check requires -dry-run or -diff")
+		os.Exit(1)
+	}
+
+	runFn := run
+	switch {
+	case *watch:
+		runFn = runWatch
+	case dryRunEnabled:
+		// Writes land on an in-memory overlay instead of the real files,
+		// so reads (for any file not yet written through it) still see
+		// the real disk.
+		e.fs = afero.NewCopyOnWriteFs(osFS, afero.NewMemMapFs())
+		runFn = runDryRun
+		anyTargetChanged = false
+	}
+
+	if err := runProfiles(e, *dataPath, paths, selected, *profile, runFn); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *check && dryRunEnabled && anyTargetChanged {
+		os.Exit(1)
+	}
+}
+
+// parseTargetSelection turns a comma-separated -targets flag value into the
+// list of target names run() should render, validating each against the
+// registry. An empty flag means "every registered target" and is reported
+// as a nil selection.
+func parseTargetSelection(flagValue string) ([]string, error) {
+	if flagValue == "" {
+		return nil, nil
+	}
+	var selected []string
+	for _, name := range strings.Split(flagValue, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := defaultTargetPaths[name]; !ok {
+			return nil, fmt.Errorf("unknown target %q", name)
+		}
+		selected = append(selected, name)
+	}
+	return selected, nil
 }
 
 func logSkip(format string, args ...any) {
@@ -110,101 +220,231 @@ func logSkip(format string, args ...any) {
 	}
 }
 
-func run(dataPath, claudePath, codexPath, opencodePath string) error {
-	root, err := resolveRoot()
+func resolvePaths(e env, dataPath string, paths targetPaths) (string, targetPaths, string, error) {
+	root, err := resolveRoot(e)
 	if err != nil {
-		return err
+		return "", nil, "", err
 	}
 
-	paths := []struct {
-		value      *string
-		defaultVal string
-	}{
-		{&dataPath, defaultDataPath},
-		{&claudePath, defaultClaudePath},
-		{&codexPath, defaultCodexPath},
-		{&opencodePath, defaultOpencodePath},
+	dataPath, err = resolveOrDefault(e, dataPath, root, defaultDataPath)
+	if err != nil {
+		return "", nil, "", err
 	}
-	for _, p := range paths {
-		*p.value, err = resolveOrDefault(*p.value, root, p.defaultVal)
+
+	resolved := make(targetPaths, len(paths))
+	for name, path := range paths {
+		def, ok := defaultTargetPaths[name]
+		if !ok {
+			return "", nil, "", fmt.Errorf("unknown target %q", name)
+		}
+		resolved[name], err = resolveOrDefault(e, path, root, def)
 		if err != nil {
-			return err
+			return "", nil, "", err
 		}
 	}
 
-	cfg, err := loadConfig(dataPath)
+	return dataPath, resolved, root, nil
+}
+
+// run regenerates the selected targets' files from dataPath (or every
+// registered target, when selected is nil), writing back whichever ones
+// changed. profile, when non-empty, overlays the named entry from
+// cfg.Profiles onto the base config and redirects targets to that
+// profile's output paths.
+func run(e env, dataPath string, paths targetPaths, selected []string, profile string) error {
+	return forEachTarget(e, dataPath, paths, selected, profile, writeTarget)
+}
+
+// forEachTarget resolves paths, loads and evaluates cfg (applying includes,
+// the named profile overlay, and policy decisions in that order), and
+// applies apply to each selected target in turn (every registered target,
+// when selected is nil). run and runDryRun share this and differ only in
+// what apply does with the result.
+func forEachTarget(e env, dataPath string, paths targetPaths, selected []string, profile string, apply func(fs afero.Fs, t Target, cfg config, path string) error) error {
+	dataPath, paths, root, err := resolvePaths(e, dataPath, paths)
 	if err != nil {
 		return err
 	}
 
-	perm := buildClaudePermissions(cfg)
-
-	if err := writeClaudePermissions(perm, claudePath); err != nil {
+	cfg, err := loadConfig(e.fs, dataPath)
+	if err != nil {
 		return err
 	}
 
-	if err := writeCodexRules(cfg, codexPath); err != nil {
+	cfg, err = resolveIncludes(e, root, cfg)
+	if err != nil {
 		return err
 	}
 
-	if err := writeOpencodePermissions(cfg, opencodePath); err != nil {
+	if profile != "" {
+		prof, ok := cfg.Profiles[profile]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", profile)
+		}
+		cfg = applyProfileOverlay(cfg, prof)
+		paths, err = overlayProfilePaths(e, root, paths, prof.Paths)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", profile, err)
+		}
+	}
+
+	if err := applyPolicyDecisions(context.Background(), e, root, &cfg); err != nil {
 		return err
 	}
 
+	names := selected
+	if names == nil {
+		names = targetNames()
+	}
+
+	for _, name := range names {
+		if err := apply(e.fs, targetRegistry[name], cfg, paths[name]); err != nil {
+			return fmt.Errorf("%s target: %w", name, err)
+		}
+	}
+
 	return nil
 }
 
-func writeClaudePermissions(perm claudePermissions, path string) error {
-	return updateFileIfChanged(path, "skipping claude: %s not found", func(contents string) (string, error) {
-		return replacePermissionsBlock(contents, perm)
-	})
+// targetOutput is what driving a single Target through Build/Render/Patch
+// produced: path is where it would be written, existing/patched are its
+// before/after bytes, and skipped explains why nothing ran (e.g. the
+// target's file isn't present on this machine) when non-empty.
+type targetOutput struct {
+	name              string
+	path              string
+	existing, patched []byte
+	skipped           string
 }
 
-func updateFileIfChanged(path, skipMsg string, transform func(string) (string, error)) error {
-	if !fileExists(path) {
-		logSkip(skipMsg, path)
-		return nil
+// buildTargetOutput builds and renders t for cfg, then patches the result
+// against path's current contents on fs. Targets that patch a block into a
+// file the user otherwise owns (Claude, Opencode, Continue) are skipped if
+// that file doesn't exist yet; targets that own their file outright
+// (fileOwnerTarget, e.g. Codex, Aider) only need their containing
+// directory to exist, since Patch ignores whatever was there before.
+func buildTargetOutput(fs afero.Fs, t Target, cfg config, path string) (targetOutput, error) {
+	out := targetOutput{name: t.Name(), path: path}
+
+	owns := false
+	if fo, ok := t.(fileOwnerTarget); ok {
+		owns = fo.OwnsFile()
+	}
+
+	if owns {
+		if !dirExists(fs, filepath.Dir(path)) {
+			out.skipped = filepath.Dir(path) + " not found"
+			return out, nil
+		}
+		if data, err := afero.ReadFile(fs, path); err == nil {
+			out.existing = data
+		}
+	} else {
+		if !fileExists(fs, path) {
+			out.skipped = path + " not found"
+			return out, nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return out, fmt.Errorf("read file: %w", err)
+		}
+		out.existing = data
 	}
 
-	contents, err := os.ReadFile(path)
+	rendered, err := runTarget(t, cfg)
 	if err != nil {
-		return fmt.Errorf("read file: %w", err)
+		return out, err
 	}
 
-	updated, err := transform(string(contents))
+	patched, err := t.Patch(out.existing, rendered)
 	if err != nil {
-		return err
+		return out, err
 	}
+	out.patched = patched
 
-	if updated == string(contents) {
+	return out, nil
+}
+
+// writeTarget builds t's output for cfg and writes it to path on fs if it
+// changed.
+func writeTarget(fs afero.Fs, t Target, cfg config, path string) error {
+	out, err := buildTargetOutput(fs, t, cfg, path)
+	if err != nil {
+		return err
+	}
+	if out.skipped != "" {
+		logSkip("skipping %s: %s", out.name, out.skipped)
+		return nil
+	}
+	if bytes.Equal(out.patched, out.existing) {
 		return nil
 	}
 
-	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+	if err := afero.WriteFile(fs, path, out.patched, 0o644); err != nil {
 		return fmt.Errorf("write file: %w", err)
 	}
-
 	return nil
 }
 
-func resolveRoot() (string, error) {
+// runAndReport behaves like run, but also returns the names of targets
+// whose output actually changed (in target order), so watch mode can print
+// a per-target summary of what a regeneration touched.
+func runAndReport(e env, dataPath string, paths targetPaths, selected []string, profile string) ([]string, error) {
+	var changed []string
+	apply := func(fs afero.Fs, t Target, cfg config, path string) error {
+		out, err := buildTargetOutput(fs, t, cfg, path)
+		if err != nil {
+			return err
+		}
+		if out.skipped != "" {
+			logSkip("skipping %s: %s", out.name, out.skipped)
+			return nil
+		}
+		if bytes.Equal(out.patched, out.existing) {
+			return nil
+		}
+		if err := afero.WriteFile(fs, path, out.patched, 0o644); err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		changed = append(changed, out.name)
+		return nil
+	}
+	return changed, forEachTarget(e, dataPath, paths, selected, profile, apply)
+}
+
+func resolveRoot(e env) (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("get working directory: %w", err)
 	}
-	return findRepoRoot(cwd)
+	return findRepoRoot(e.fs, cwd)
 }
 
-func resolveOrDefault(path, root, defaultPath string) (string, error) {
+func resolveOrDefault(e env, path, root, defaultPath string) (string, error) {
 	if path == "" {
 		return filepath.Join(root, defaultPath), nil
 	}
-	return resolvePath(path)
+	return resolveRelative(e, root, path)
+}
+
+// resolveRelative expands a leading "~" against e.home and otherwise joins
+// path against root when it isn't already absolute, so a path written in
+// permissions.yaml (a target path, a policy file, a local include) resolves
+// the same way regardless of the directory permissions-gen was invoked
+// from - matching findRepoRoot's "run from any subdirectory" design.
+func resolveRelative(e env, root, path string) (string, error) {
+	if strings.HasPrefix(path, "~") {
+		return resolvePath(e.home, path)
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(root, path), nil
 }
 
-func resolvePath(path string) (string, error) {
+func resolvePath(home, path string) (string, error) {
 	if strings.HasPrefix(path, "~") {
-		expanded, err := expandHome(path)
+		expanded, err := expandHome(home, path)
 		if err != nil {
 			return "", err
 		}
@@ -220,10 +460,10 @@ func resolvePath(path string) (string, error) {
 	return abs, nil
 }
 
-func findRepoRoot(start string) (string, error) {
+func findRepoRoot(fs afero.Fs, start string) (string, error) {
 	dir := start
 	for {
-		if fileExists(filepath.Join(dir, defaultDataPath)) {
+		if fileExists(fs, filepath.Join(dir, defaultDataPath)) {
 			return dir, nil
 		}
 		parent := filepath.Dir(dir)
@@ -235,13 +475,12 @@ func findRepoRoot(start string) (string, error) {
 	return "", fmt.Errorf("could not locate repo root from %s", start)
 }
 
-func expandHome(path string) (string, error) {
+func expandHome(home, path string) (string, error) {
 	if !strings.HasPrefix(path, "~") {
 		return path, nil
 	}
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("resolve home: %w", err)
+	if home == "" {
+		return "", fmt.Errorf("resolve home: no home directory configured")
 	}
 	if path == "~" {
 		return home, nil
@@ -252,18 +491,18 @@ func expandHome(path string) (string, error) {
 	return "", fmt.Errorf("unsupported home path: %s", path)
 }
 
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
+func fileExists(fs afero.Fs, path string) bool {
+	info, err := fs.Stat(path)
 	return err == nil && !info.IsDir()
 }
 
-func dirExists(path string) bool {
-	info, err := os.Stat(path)
+func dirExists(fs afero.Fs, path string) bool {
+	info, err := fs.Stat(path)
 	return err == nil && info.IsDir()
 }
 
-func loadConfig(path string) (config, error) {
-	data, err := os.ReadFile(path)
+func loadConfig(fs afero.Fs, path string) (config, error) {
+	data, err := afero.ReadFile(fs, path)
 	if err != nil {
 		return config{}, fmt.Errorf("read data: %w", err)
 	}
@@ -276,106 +515,6 @@ func loadConfig(path string) (config, error) {
 	return cfg, nil
 }
 
-func buildClaudePermissions(cfg config) claudePermissions {
-	allow := expandWithBash(cfg.Claude.Allow, cfg.Bash.Allow)
-	ask := expandWithBash(cfg.Claude.Ask, cfg.Bash.Ask)
-	deny := expandWithBash(cfg.Claude.Deny, cfg.Bash.Deny)
-
-	return claudePermissions{
-		Allow:                 allow,
-		Ask:                   ensureSlice(ask),
-		Deny:                  ensureSlice(deny),
-		AdditionalDirectories: ensureSlice(normalizeList(cfg.Claude.AdditionalDirectories, false)),
-	}
-}
-
-func replacePermissionsBlock(contents string, perm claudePermissions) (string, error) {
-	start := strings.Index(contents, startMarker)
-	end := strings.Index(contents, endMarker)
-
-	if start != -1 && end != -1 && start < end {
-		return replaceWithMarkers(contents, perm, start, end)
-	}
-
-	return replacePermissionsJSON(contents, perm)
-}
-
-func replaceWithMarkers(contents string, perm claudePermissions, start, end int) (string, error) {
-	lines, err := permissionsLines(perm)
-	if err != nil {
-		return "", err
-	}
-
-	return replaceBlockWithLines(contents, start, end, lines)
-}
-
-func replaceBlockWithLines(contents string, start, end int, lines []string) (string, error) {
-	indent, err := lineIndent(contents, start)
-	if err != nil {
-		return "", err
-	}
-
-	for i, line := range lines {
-		lines[i] = indent + line
-	}
-
-	block := startMarker + "\n" + strings.Join(lines, "\n") + "\n" + indent + endMarker
-
-	return contents[:start] + block + contents[end+len(endMarker):], nil
-}
-
-func replacePermissionsJSON(contents string, perm claudePermissions) (string, error) {
-	keyPos, objStart, objEnd, err := findObjectForKey(contents, "permissions")
-	if err != nil {
-		return "", fmt.Errorf("permissions object not found: %w", err)
-	}
-
-	data, err := json.MarshalIndent(perm, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("marshal permissions: %w", err)
-	}
-
-	indent := lineIndentForPos(contents, keyPos)
-	replacement := indentMultilineValue(string(data), indent)
-
-	return contents[:objStart] + replacement + contents[objEnd+1:], nil
-}
-
-func lineIndent(contents string, markerPos int) (string, error) {
-	lineStart := strings.LastIndex(contents[:markerPos], "\n") + 1
-	indent := contents[lineStart:markerPos]
-	if strings.TrimSpace(indent) != "" {
-		return "", fmt.Errorf("marker must be on its own line: %q", indent)
-	}
-	return indent, nil
-}
-
-func permissionsLines(perm claudePermissions) ([]string, error) {
-	data, err := json.MarshalIndent(perm, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("marshal permissions: %w", err)
-	}
-	return innerJSONLines(string(data))
-}
-
-func innerJSONLines(data string) ([]string, error) {
-	lines := strings.Split(data, "\n")
-	if len(lines) < 2 {
-		return nil, fmt.Errorf("unexpected json: too few lines")
-	}
-
-	inner := lines[1 : len(lines)-1]
-	for i, line := range inner {
-		if trimmed, ok := strings.CutPrefix(line, "  "); ok {
-			inner[i] = trimmed
-			continue
-		}
-		inner[i] = line
-	}
-
-	return inner, nil
-}
-
 func toBashPatterns(values []string) []string {
 	normalized := normalizeList(values, false)
 	out := make([]string, 0, len(normalized))
@@ -463,401 +602,3 @@ func ensureSlice(values []string) []string {
 	}
 	return values
 }
-
-type codexRule struct {
-	PatternPrefix []string
-	PatternAlts   []string
-	Decision      string
-	Match         string
-}
-
-func writeCodexRules(cfg config, path string) error {
-	dir := filepath.Dir(path)
-	if !dirExists(dir) {
-		logSkip("skipping codex: %s not found", dir)
-		return nil
-	}
-
-	rules := buildCodexRules(cfg)
-	content := renderCodexRules(rules)
-	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-		return fmt.Errorf("write codex rules: %w", err)
-	}
-	return nil
-}
-
-func buildCodexRules(cfg config) []codexRule {
-	var rules []codexRule
-	rules = append(rules, buildCodexDecisionRules("allow", cfg.Bash.Allow)...)
-	rules = append(rules, buildCodexDecisionRules("prompt", cfg.Bash.Ask)...)
-	rules = append(rules, buildCodexDecisionRules("forbidden", cfg.Bash.Deny)...)
-	return rules
-}
-
-type commandGroup struct {
-	prefix []string
-	alts   []string
-	seen   map[string]struct{}
-}
-
-type groupedCommands struct {
-	order   []string
-	groups  map[string]*commandGroup
-	singles map[string][]string
-}
-
-func groupCommands(commands []string) groupedCommands {
-	gc := groupedCommands{
-		groups:  make(map[string]*commandGroup),
-		singles: make(map[string][]string),
-	}
-
-	for _, cmd := range commands {
-		tokens := strings.Fields(cmd)
-		if len(tokens) == 0 {
-			continue
-		}
-		if len(tokens) == 1 {
-			key := "single|" + tokens[0]
-			if _, ok := gc.singles[key]; !ok {
-				gc.singles[key] = tokens
-				gc.order = append(gc.order, key)
-			}
-			continue
-		}
-
-		prefix := strings.Join(tokens[:len(tokens)-1], "\x1f")
-		key := fmt.Sprintf("group|%d|%s", len(tokens), prefix)
-		if _, ok := gc.groups[key]; !ok {
-			gc.groups[key] = &commandGroup{
-				prefix: tokens[:len(tokens)-1],
-				alts:   []string{},
-				seen:   make(map[string]struct{}),
-			}
-			gc.order = append(gc.order, key)
-		}
-
-		last := tokens[len(tokens)-1]
-		if _, ok := gc.groups[key].seen[last]; ok {
-			continue
-		}
-		gc.groups[key].seen[last] = struct{}{}
-		gc.groups[key].alts = append(gc.groups[key].alts, last)
-	}
-
-	return gc
-}
-
-func buildCodexDecisionRules(decision string, commands []string) []codexRule {
-	gc := groupCommands(commands)
-
-	var rules []codexRule
-	for _, key := range gc.order {
-		if tokens, ok := gc.singles[key]; ok {
-			rules = append(rules, codexRule{
-				PatternPrefix: tokens,
-				Decision:      decision,
-				Match:         strings.Join(tokens, " "),
-			})
-			continue
-		}
-		group := gc.groups[key]
-		if group == nil {
-			continue
-		}
-		if len(group.alts) == 1 {
-			full := append([]string{}, group.prefix...)
-			full = append(full, group.alts[0])
-			rules = append(rules, codexRule{
-				PatternPrefix: full,
-				Decision:      decision,
-				Match:         strings.Join(full, " "),
-			})
-			continue
-		}
-		matchTokens := append([]string{}, group.prefix...)
-		matchTokens = append(matchTokens, group.alts[0])
-		rules = append(rules, codexRule{
-			PatternPrefix: group.prefix,
-			PatternAlts:   group.alts,
-			Decision:      decision,
-			Match:         strings.Join(matchTokens, " "),
-		})
-	}
-
-	return rules
-}
-
-func renderCodexRules(rules []codexRule) string {
-	var builder strings.Builder
-	builder.WriteString("# ~/.codex/rules/default.rules\n")
-	builder.WriteString("# Generated by tools/permissions-gen. Do not edit by hand.\n\n")
-	for i, rule := range rules {
-		builder.WriteString("prefix_rule(\n")
-		builder.WriteString(renderCodexPattern(rule))
-		builder.WriteString(renderCodexDecision(rule.Decision))
-		builder.WriteString(renderCodexMatch(rule.Match))
-		builder.WriteString(")\n")
-		if i < len(rules)-1 {
-			builder.WriteString("\n")
-		}
-	}
-	return builder.String()
-}
-
-func renderCodexPattern(rule codexRule) string {
-	if len(rule.PatternAlts) == 0 {
-		return fmt.Sprintf("  pattern = [%s],\n", joinQuoted(rule.PatternPrefix))
-	}
-	var builder strings.Builder
-	builder.WriteString("  pattern = [")
-	builder.WriteString(joinQuoted(rule.PatternPrefix))
-	builder.WriteString(", [\n")
-	for _, alt := range rule.PatternAlts {
-		fmt.Fprintf(&builder, "    %q,\n", alt)
-	}
-	builder.WriteString("  ]],\n")
-	return builder.String()
-}
-
-func renderCodexDecision(decision string) string {
-	if decision == "" || decision == "allow" {
-		return "  decision = \"allow\",\n"
-	}
-	return fmt.Sprintf("  decision = %q,\n", decision)
-}
-
-func renderCodexMatch(match string) string {
-	if strings.TrimSpace(match) == "" {
-		return ""
-	}
-	return fmt.Sprintf("  match = [%q],\n", match)
-}
-
-func joinQuoted(tokens []string) string {
-	parts := make([]string, 0, len(tokens))
-	for _, token := range tokens {
-		parts = append(parts, fmt.Sprintf("%q", token))
-	}
-	return strings.Join(parts, ", ")
-}
-
-type opencodeRule struct {
-	Pattern  string
-	Decision string
-}
-
-func writeOpencodePermissions(cfg config, path string) error {
-	sections := buildOpencodeSections(cfg)
-	permissionsJSON := renderOpencodePermissionsJSON(sections)
-	lines, err := opencodePermissionsLinesFromJSON(permissionsJSON)
-	if err != nil {
-		return err
-	}
-
-	return updateFileIfChanged(path, "skipping opencode: %s not found", func(contents string) (string, error) {
-		return replaceOpencodePermissions(contents, permissionsJSON, lines)
-	})
-}
-
-type opencodeSection struct {
-	Name     string
-	Rules    []opencodeRule
-	Scalar   string
-	IsScalar bool
-}
-
-func buildOpencodeSections(cfg config) []opencodeSection {
-	var sections []opencodeSection
-	if cfg.Opencode.Bash.IsScalar {
-		sections = append(sections, opencodeSection{
-			Name:     "bash",
-			Scalar:   cfg.Opencode.Bash.Scalar,
-			IsScalar: true,
-		})
-	} else {
-		sections = append(sections, opencodeSection{
-			Name:  "bash",
-			Rules: buildOpencodeBashRules(cfg),
-		})
-	}
-
-	if len(cfg.Opencode.Others) == 0 {
-		return sections
-	}
-
-	var names []string
-	for name := range cfg.Opencode.Others {
-		if name == "bash" {
-			continue
-		}
-		names = append(names, name)
-	}
-	sort.Strings(names)
-	for _, name := range names {
-		section := cfg.Opencode.Others[name]
-		if section.IsScalar {
-			sections = append(sections, opencodeSection{
-				Name:     name,
-				Scalar:   section.Scalar,
-				IsScalar: true,
-			})
-			continue
-		}
-		rules := buildOpencodeSectionRules(section)
-		sections = append(sections, opencodeSection{Name: name, Rules: rules})
-	}
-	return sections
-}
-
-func buildOpencodeBashRules(cfg config) []opencodeRule {
-	return buildOpencodeRulesForSection(
-		cfg.Opencode.Bash.Default,
-		append(cfg.Bash.Allow, cfg.Opencode.Bash.Allow...),
-		append(cfg.Bash.Ask, cfg.Opencode.Bash.Ask...),
-		append(cfg.Bash.Deny, cfg.Opencode.Bash.Deny...),
-		true,
-	)
-}
-
-func buildOpencodeSectionRules(section opencodeSectionConfig) []opencodeRule {
-	return buildOpencodeRulesForSection(
-		section.Default,
-		section.Allow,
-		section.Ask,
-		section.Deny,
-		false,
-	)
-}
-
-func buildOpencodeRulesForSection(defaultDecision string, allow, ask, deny []string, expand bool) []opencodeRule {
-	decision := strings.TrimSpace(defaultDecision)
-	if decision == "" {
-		decision = "allow"
-	}
-
-	rules := []opencodeRule{{Pattern: "*", Decision: decision}}
-	rules = append(rules, buildOpencodeDecisionRules("allow", allow, expand)...)
-	rules = append(rules, buildOpencodeDecisionRules("ask", ask, expand)...)
-	rules = append(rules, buildOpencodeDecisionRules("deny", deny, expand)...)
-	return rules
-}
-
-func buildOpencodeDecisionRules(decision string, values []string, expand bool) []opencodeRule {
-	var patterns []string
-	if expand {
-		patterns = expandOpencodePatterns(values)
-	} else {
-		patterns = normalizeList(values, true)
-	}
-	rules := make([]opencodeRule, 0, len(patterns))
-	for _, pattern := range patterns {
-		rules = append(rules, opencodeRule{
-			Pattern:  pattern,
-			Decision: decision,
-		})
-	}
-	return rules
-}
-
-func expandOpencodePatterns(values []string) []string {
-	seen := make(map[string]struct{})
-	var out []string
-	for _, value := range values {
-		trimmed := strings.TrimSpace(value)
-		if trimmed == "" {
-			continue
-		}
-		out, seen = appendUnique(out, seen, trimmed)
-		if !containsWildcard(trimmed) {
-			out, seen = appendUnique(out, seen, trimmed+" *")
-		}
-	}
-	return out
-}
-
-func containsWildcard(value string) bool {
-	return strings.ContainsAny(value, "*?")
-}
-
-func renderOpencodeSectionJSON(rules []opencodeRule) string {
-	var builder strings.Builder
-	builder.WriteString("{\n")
-	for i, rule := range rules {
-		builder.WriteString("  ")
-		builder.WriteString(jsonString(rule.Pattern))
-		builder.WriteString(": ")
-		builder.WriteString(jsonString(rule.Decision))
-		if i < len(rules)-1 {
-			builder.WriteString(",")
-		}
-		builder.WriteString("\n")
-	}
-	builder.WriteString("}")
-	return builder.String()
-}
-
-func renderOpencodePermissionsJSON(sections []opencodeSection) string {
-	var builder strings.Builder
-	builder.WriteString("{\n")
-	for i, section := range sections {
-		builder.WriteString("  ")
-		builder.WriteString(jsonString(section.Name))
-		builder.WriteString(": ")
-		if section.IsScalar {
-			builder.WriteString(jsonString(section.Scalar))
-		} else {
-			builder.WriteString(indentMultilineValue(renderOpencodeSectionJSON(section.Rules), "  "))
-		}
-		if i < len(sections)-1 {
-			builder.WriteString(",")
-		}
-		builder.WriteString("\n")
-	}
-	builder.WriteString("}")
-	return builder.String()
-}
-
-func opencodePermissionsLinesFromJSON(permissionsJSON string) ([]string, error) {
-	return innerJSONLines(permissionsJSON)
-}
-
-func replaceOpencodePermissions(contents, permissionsJSON string, lines []string) (string, error) {
-	start := strings.Index(contents, startMarker)
-	end := strings.Index(contents, endMarker)
-	if start == -1 || end == -1 || start >= end {
-		return replaceOpencodePermissionsJSON(contents, permissionsJSON)
-	}
-	return replaceBlockWithLines(contents, start, end, lines)
-}
-
-func replaceOpencodePermissionsJSON(contents, permissionsJSON string) (string, error) {
-	permKeyPos, permStart, permEnd, err := findObjectForKey(contents, "permission")
-	if err != nil {
-		return "", err
-	}
-	indent := lineIndentForPos(contents, permKeyPos)
-	replacement := indentMultilineValue(permissionsJSON, indent)
-	return contents[:permStart] + replacement + contents[permEnd+1:], nil
-}
-
-func indentMultilineValue(value, indent string) string {
-	lines := strings.Split(value, "\n")
-	for i := 1; i < len(lines); i++ {
-		lines[i] = indent + lines[i]
-	}
-	return strings.Join(lines, "\n")
-}
-
-func lineIndentForPos(contents string, pos int) string {
-	lineStart := strings.LastIndex(contents[:pos], "\n") + 1
-	return contents[lineStart:pos]
-}
-
-func jsonString(value string) string {
-	data, err := json.Marshal(value)
-	if err != nil {
-		return fmt.Sprintf("%q", value)
-	}
-	return string(data)
-}
@@ -0,0 +1,102 @@
+package main
+
+// mergeConfig folds fragment (an included permissions.yaml) into base:
+// allow/ask/deny lists are concatenated and deduped, opencode's Others
+// sections are merged per key, and a deny entry from either side always
+// wins over an allow/ask entry for the same command from the other side.
+func mergeConfig(base, fragment config) config {
+	base.Bash = mergeBashConfig(base.Bash, fragment.Bash)
+	base.Claude = mergeClaudeConfig(base.Claude, fragment.Claude)
+	base.Opencode = mergeOpencodeConfig(base.Opencode, fragment.Opencode)
+	return base
+}
+
+func mergeBashConfig(a, b bashConfig) bashConfig {
+	deny := mergeUnique(a.Deny, b.Deny)
+	return bashConfig{
+		Allow: subtract(mergeUnique(a.Allow, b.Allow), deny),
+		Ask:   subtract(mergeUnique(a.Ask, b.Ask), deny),
+		Deny:  deny,
+	}
+}
+
+func mergeClaudeConfig(a, b claudeConfig) claudeConfig {
+	deny := mergeUnique(a.Deny, b.Deny)
+	return claudeConfig{
+		Allow:                 subtract(mergeUnique(a.Allow, b.Allow), deny),
+		Ask:                   subtract(mergeUnique(a.Ask, b.Ask), deny),
+		Deny:                  deny,
+		AdditionalDirectories: mergeUnique(a.AdditionalDirectories, b.AdditionalDirectories),
+	}
+}
+
+func mergeOpencodeConfig(a, b opencodeConfig) opencodeConfig {
+	merged := opencodeConfig{
+		Bash:   mergeOpencodeSectionConfig(a.Bash, b.Bash),
+		Others: map[string]opencodeSectionConfig{},
+	}
+	for name, section := range a.Others {
+		merged.Others[name] = section
+	}
+	for name, section := range b.Others {
+		if existing, ok := merged.Others[name]; ok {
+			merged.Others[name] = mergeOpencodeSectionConfig(existing, section)
+		} else {
+			merged.Others[name] = section
+		}
+	}
+	if len(merged.Others) == 0 {
+		merged.Others = nil
+	}
+	return merged
+}
+
+// mergeOpencodeSectionConfig merges two section configs. A scalar section
+// (the "bash: ask" shorthand) is an outright decision for the whole
+// section, not a list to merge, so the base's scalar always wins over an
+// included fragment's list or scalar for that section.
+func mergeOpencodeSectionConfig(a, b opencodeSectionConfig) opencodeSectionConfig {
+	if a.IsScalar {
+		return a
+	}
+	if b.IsScalar && isEmptyOpencodeSection(a) {
+		return b
+	}
+
+	deny := mergeUnique(a.Deny, b.Deny)
+	defaultDecision := a.Default
+	if defaultDecision == "" {
+		defaultDecision = b.Default
+	}
+	return opencodeSectionConfig{
+		Default: defaultDecision,
+		Allow:   subtract(mergeUnique(a.Allow, b.Allow), deny),
+		Ask:     subtract(mergeUnique(a.Ask, b.Ask), deny),
+		Deny:    deny,
+	}
+}
+
+func isEmptyOpencodeSection(s opencodeSectionConfig) bool {
+	return !s.IsScalar && s.Default == "" && len(s.Allow) == 0 && len(s.Ask) == 0 && len(s.Deny) == 0
+}
+
+// subtract removes any item present in deny from list, preserving list's
+// order. It's how a deny entry from one config wins over an allow/ask
+// entry for the same command from another.
+func subtract(list, deny []string) []string {
+	if len(deny) == 0 {
+		return list
+	}
+	denySet := make(map[string]struct{}, len(deny))
+	for _, d := range deny {
+		denySet[d] = struct{}{}
+	}
+	var out []string
+	for _, item := range list {
+		if _, ok := denySet[item]; ok {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
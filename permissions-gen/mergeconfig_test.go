@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeConfig_DenyWinsAcrossBundles(t *testing.T) {
+	base := config{
+		Bash: bashConfig{Allow: []string{"git", "rm"}},
+	}
+	fragment := config{
+		Bash: bashConfig{Deny: []string{"rm"}, Allow: []string{"ls"}},
+	}
+
+	got := mergeConfig(base, fragment)
+	want := bashConfig{Allow: []string{"git", "ls"}, Deny: []string{"rm"}}
+	if !reflect.DeepEqual(got.Bash, want) {
+		t.Fatalf("mergeConfig().Bash = %#v, want %#v", got.Bash, want)
+	}
+}
+
+func TestMergeConfig_OpencodeOthersMergedPerKey(t *testing.T) {
+	base := config{
+		Opencode: opencodeConfig{
+			Others: map[string]opencodeSectionConfig{
+				"webfetch": {Allow: []string{"a"}},
+			},
+		},
+	}
+	fragment := config{
+		Opencode: opencodeConfig{
+			Others: map[string]opencodeSectionConfig{
+				"webfetch": {Allow: []string{"b"}, Deny: []string{"a"}},
+				"edit":     {Allow: []string{"c"}},
+			},
+		},
+	}
+
+	got := mergeConfig(base, fragment)
+
+	webfetch := got.Opencode.Others["webfetch"]
+	if !reflect.DeepEqual(webfetch.Allow, []string{"b"}) || !reflect.DeepEqual(webfetch.Deny, []string{"a"}) {
+		t.Fatalf("merged webfetch section = %#v", webfetch)
+	}
+
+	edit, ok := got.Opencode.Others["edit"]
+	if !ok || !reflect.DeepEqual(edit.Allow, []string{"c"}) {
+		t.Fatalf("merged edit section = %#v, ok=%v", edit, ok)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// policyInput describes a candidate command that a Rego policy can classify.
+type policyInput struct {
+	Cmd    string   `yaml:"cmd"`
+	Args   []string `yaml:"args"`
+	Tags   []string `yaml:"tags"`
+	Source string   `yaml:"source"`
+}
+
+func (p policyInput) command() string {
+	parts := append([]string{p.Cmd}, p.Args...)
+	return strings.Join(parts, " ")
+}
+
+func (p policyInput) document() map[string]any {
+	return map[string]any{
+		"cmd":    p.Cmd,
+		"args":   p.Args,
+		"tags":   p.Tags,
+		"source": p.Source,
+	}
+}
+
+// applyPolicyDecisions evaluates cfg.Policies against cfg.PolicyInputs and
+// folds the resulting allow/ask/deny decisions into cfg.Bash. Static YAML
+// entries always take precedence over a policy decision for the same
+// command. Policy paths are resolved relative to root, the same as target
+// and include paths, so a relative entry in permissions.yaml works the same
+// whether permissions-gen runs from the repo root or a subdirectory.
+func applyPolicyDecisions(ctx context.Context, e env, root string, cfg *config) error {
+	if len(cfg.Policies) == 0 {
+		return nil
+	}
+
+	policies := make([]string, len(cfg.Policies))
+	for i, p := range cfg.Policies {
+		resolved, err := resolveRelative(e, root, p)
+		if err != nil {
+			return fmt.Errorf("resolve policy %s: %w", p, err)
+		}
+		policies[i] = resolved
+	}
+
+	query, err := compilePolicies(ctx, policies)
+	if err != nil {
+		return err
+	}
+
+	static := staticBashCommands(cfg.Bash)
+
+	for _, input := range cfg.PolicyInputs {
+		cmd := input.command()
+		if _, ok := static[cmd]; ok {
+			continue
+		}
+
+		decision, err := evaluatePolicy(ctx, query, input)
+		if err != nil {
+			return fmt.Errorf("evaluate policy for %+v: %w", input.document(), err)
+		}
+
+		switch decision {
+		case "allow":
+			cfg.Bash.Allow = append(cfg.Bash.Allow, cmd)
+		case "ask":
+			cfg.Bash.Ask = append(cfg.Bash.Ask, cmd)
+		case "deny":
+			cfg.Bash.Deny = append(cfg.Bash.Deny, cmd)
+		case "skip":
+			// explicitly opt out of a decision for this command
+		default:
+			return fmt.Errorf("policy returned unknown decision %q for input %+v", decision, input.document())
+		}
+	}
+
+	return nil
+}
+
+func compilePolicies(ctx context.Context, paths []string) (rego.PreparedEvalQuery, error) {
+	r := rego.New(
+		rego.Load(paths, nil),
+		rego.Query("data.dotfiles.permissions.decision"),
+	)
+
+	query, err := r.PrepareForEval(ctx)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("compile policies %v: %w", paths, err)
+	}
+	return query, nil
+}
+
+func evaluatePolicy(ctx context.Context, query rego.PreparedEvalQuery, input policyInput) (string, error) {
+	results, err := query.Eval(ctx, rego.EvalInput(input.document()))
+	if err != nil {
+		return "", fmt.Errorf("policy evaluation failed: %w", err)
+	}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return "", fmt.Errorf("policy produced no decision")
+	}
+
+	decision, ok := results[0].Expressions[0].Value.(string)
+	if !ok {
+		return "", fmt.Errorf("policy decision was not a string, got %#v", results[0].Expressions[0].Value)
+	}
+	return decision, nil
+}
+
+func staticBashCommands(bash bashConfig) map[string]struct{} {
+	seen := make(map[string]struct{})
+	for _, list := range [][]string{bash.Allow, bash.Ask, bash.Deny} {
+		for _, cmd := range list {
+			seen[strings.TrimSpace(cmd)] = struct{}{}
+		}
+	}
+	return seen
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStaticBashCommands(t *testing.T) {
+	bash := bashConfig{
+		Allow: []string{"git status", " git log "},
+		Ask:   []string{"cp"},
+		Deny:  []string{"rm"},
+	}
+
+	got := staticBashCommands(bash)
+	want := map[string]struct{}{
+		"git status": {},
+		"git log":    {},
+		"cp":         {},
+		"rm":         {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("staticBashCommands() = %#v, want %#v", got, want)
+	}
+}
+
+func TestPolicyInput_Command(t *testing.T) {
+	input := policyInput{Cmd: "git", Args: []string{"push", "--force"}}
+	got := input.command()
+	want := "git push --force"
+	if got != want {
+		t.Fatalf("policyInput.command() = %q, want %q", got, want)
+	}
+}
+
+// TestApplyPolicyDecisions_EndToEnd loads a real .rego file (referenced by
+// a path relative to root, not the process's cwd) and checks that its
+// per-command decisions land in cfg.Bash, that a static YAML entry for the
+// same command still wins over the policy, and that "skip" leaves a
+// command out of every list.
+func TestApplyPolicyDecisions_EndToEnd(t *testing.T) {
+	root := t.TempDir()
+	policyDir := filepath.Join(root, "policies")
+	if err := os.MkdirAll(policyDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	rego := `package dotfiles.permissions
+
+import rego.v1
+
+decision := "deny" if {
+	input.cmd == "git"
+	input.args[0] == "push"
+} else := "allow" if {
+	input.cmd == "ls"
+} else := "ask" if {
+	input.cmd == "curl"
+} else := "skip"
+`
+	if err := os.WriteFile(filepath.Join(policyDir, "test.rego"), []byte(rego), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg := config{
+		Bash: bashConfig{
+			Allow: []string{"ls"}, // static entry must win over the policy's "allow" for the same command
+		},
+		Policies: []string{"policies/test.rego"}, // relative to root, not cwd
+		PolicyInputs: []policyInput{
+			{Cmd: "git", Args: []string{"push", "--force"}},
+			{Cmd: "ls"},
+			{Cmd: "curl"},
+			{Cmd: "echo", Args: []string{"hi"}},
+		},
+	}
+
+	if err := applyPolicyDecisions(context.Background(), env{}, root, &cfg); err != nil {
+		t.Fatalf("applyPolicyDecisions() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(cfg.Bash.Allow, []string{"ls"}) {
+		t.Fatalf("cfg.Bash.Allow = %#v, want %#v (static entry should win, not gain a duplicate)", cfg.Bash.Allow, []string{"ls"})
+	}
+	if !reflect.DeepEqual(cfg.Bash.Ask, []string{"curl"}) {
+		t.Fatalf("cfg.Bash.Ask = %#v, want %#v", cfg.Bash.Ask, []string{"curl"})
+	}
+	if !reflect.DeepEqual(cfg.Bash.Deny, []string{"git push --force"}) {
+		t.Fatalf("cfg.Bash.Deny = %#v, want %#v", cfg.Bash.Deny, []string{"git push --force"})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// profileConfig is a named authorization context (e.g. "trusted",
+// "sandbox", "ci") that overlays the base bash/claude/opencode sections
+// and may redirect specific targets to their own output paths.
+type profileConfig struct {
+	Bash     bashConfig     `yaml:"bash"`
+	Claude   claudeConfig   `yaml:"claude"`
+	Opencode opencodeConfig `yaml:"opencode"`
+	Paths    targetPaths    `yaml:"paths"`
+}
+
+// applyProfileOverlay merges prof's sections onto cfg the same way an
+// include fragment does: arrays merged through mergeUnique, with deny
+// still winning over allow/ask.
+func applyProfileOverlay(cfg config, prof profileConfig) config {
+	return mergeConfig(cfg, config{Bash: prof.Bash, Claude: prof.Claude, Opencode: prof.Opencode})
+}
+
+// overlayProfilePaths resolves any target paths a profile overrides
+// (relative to root, same as the base paths) and layers them onto base,
+// leaving targets the profile doesn't mention untouched.
+func overlayProfilePaths(e env, root string, base, overrides targetPaths) (targetPaths, error) {
+	if len(overrides) == 0 {
+		return base, nil
+	}
+
+	resolved := make(targetPaths, len(base))
+	for name, path := range base {
+		resolved[name] = path
+	}
+	for name, path := range overrides {
+		if path == "" {
+			continue
+		}
+		def, ok := defaultTargetPaths[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown target %q in profile paths", name)
+		}
+		p, err := resolveOrDefault(e, path, root, def)
+		if err != nil {
+			return nil, err
+		}
+		resolved[name] = p
+	}
+	return resolved, nil
+}
+
+// checkProfilePathCollisions resolves each named profile's effective output
+// paths (overlaying base the same way forEachTarget will) and fails fast if
+// two profiles would write the same target to the same file - e.g. because
+// neither overrides paths.claude and both fall back to the same default.
+// Without this, --profile all silently has the alphabetically-last profile
+// clobber every earlier one's output for that target.
+func checkProfilePathCollisions(e env, root string, base targetPaths, profiles map[string]profileConfig, names []string) error {
+	owner := make(map[string]string, len(names)*len(base))
+	for _, name := range names {
+		resolved, err := overlayProfilePaths(e, root, base, profiles[name].Paths)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+		for target, path := range resolved {
+			key := target + "\x00" + path
+			if other, ok := owner[key]; ok {
+				return fmt.Errorf("--profile all: profiles %q and %q both write %s to %s; give one a paths.%s override", other, name, target, path, target)
+			}
+			owner[key] = name
+		}
+	}
+	return nil
+}
+
+// runProfiles runs runOne once for profile, or once per profile defined in
+// dataPath (in sorted-name order) when profile is "all".
+func runProfiles(e env, dataPath string, paths targetPaths, selected []string, profile string, runOne func(env, string, targetPaths, []string, string) error) error {
+	if profile != "all" {
+		return runOne(e, dataPath, paths, selected, profile)
+	}
+
+	resolvedData, resolvedPaths, root, err := resolvePaths(e, dataPath, paths)
+	if err != nil {
+		return err
+	}
+	paths = resolvedPaths
+	cfg, err := loadConfig(e.fs, resolvedData)
+	if err != nil {
+		return err
+	}
+	cfg, err = resolveIncludes(e, root, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Profiles) == 0 {
+		return fmt.Errorf("--profile all: no profiles defined in %s", resolvedData)
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := checkProfilePathCollisions(e, root, paths, cfg.Profiles, names); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := runOne(e, dataPath, paths, selected, name); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestApplyProfileOverlay_DenyWins(t *testing.T) {
+	cfg := config{
+		Bash: bashConfig{Allow: []string{"git", "curl"}},
+	}
+	prof := profileConfig{
+		Bash: bashConfig{Deny: []string{"curl"}},
+	}
+
+	got := applyProfileOverlay(cfg, prof)
+	want := bashConfig{Allow: []string{"git"}, Deny: []string{"curl"}}
+	if !reflect.DeepEqual(got.Bash, want) {
+		t.Fatalf("applyProfileOverlay().Bash = %#v, want %#v", got.Bash, want)
+	}
+}
+
+func TestOverlayProfilePaths(t *testing.T) {
+	e := env{fs: afero.NewMemMapFs(), home: "/home/test"}
+	base := targetPaths{"claude": "/repo/dot_claude/settings.json.tmpl", "codex": "/repo/dot_codex/rules/default.rules"}
+	overrides := targetPaths{"claude": "dot_claude/settings.sandbox.json.tmpl"}
+
+	got, err := overlayProfilePaths(e, "/repo", base, overrides)
+	if err != nil {
+		t.Fatalf("overlayProfilePaths() error = %v", err)
+	}
+	if got["claude"] != "/repo/dot_claude/settings.sandbox.json.tmpl" {
+		t.Fatalf("overlayProfilePaths()[claude] = %q", got["claude"])
+	}
+	if got["codex"] != base["codex"] {
+		t.Fatalf("overlayProfilePaths()[codex] = %q, want unchanged %q", got["codex"], base["codex"])
+	}
+}
+
+func TestOverlayProfilePaths_UnknownTarget(t *testing.T) {
+	e := env{fs: afero.NewMemMapFs(), home: "/home/test"}
+	_, err := overlayProfilePaths(e, "/repo", targetPaths{}, targetPaths{"nope": "x"})
+	if err == nil {
+		t.Fatal("overlayProfilePaths() expected error for unknown target")
+	}
+}
+
+func TestCheckProfilePathCollisions_SharedDefaultFails(t *testing.T) {
+	e := env{fs: afero.NewMemMapFs(), home: "/home/test"}
+	base := targetPaths{"claude": "/repo/dot_claude/settings.json.tmpl"}
+	profiles := map[string]profileConfig{
+		"aaa": {Bash: bashConfig{Allow: []string{"curl"}}},
+		"bbb": {Bash: bashConfig{Allow: []string{"wget"}}},
+	}
+
+	err := checkProfilePathCollisions(e, "/repo", base, profiles, []string{"aaa", "bbb"})
+	if err == nil {
+		t.Fatal("checkProfilePathCollisions() expected error when profiles share an output path")
+	}
+}
+
+func TestCheckProfilePathCollisions_DistinctPathsOK(t *testing.T) {
+	e := env{fs: afero.NewMemMapFs(), home: "/home/test"}
+	base := targetPaths{"claude": "/repo/dot_claude/settings.json.tmpl"}
+	profiles := map[string]profileConfig{
+		"aaa": {Paths: targetPaths{"claude": "dot_claude/settings.aaa.json.tmpl"}},
+		"bbb": {Paths: targetPaths{"claude": "dot_claude/settings.bbb.json.tmpl"}},
+	}
+
+	if err := checkProfilePathCollisions(e, "/repo", base, profiles, []string{"aaa", "bbb"}); err != nil {
+		t.Fatalf("checkProfilePathCollisions() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// aiderConfig is the subset of .aider.conf.yml this generator owns: a flat
+// allow/disallow list of shell patterns. Aider has no middle "ask" tier, so
+// buildAiderConfig folds cfg.Bash.Ask into Disallow rather than silently
+// granting those commands.
+type aiderConfig struct {
+	Allow    []string `yaml:"allow"`
+	Disallow []string `yaml:"disallow"`
+}
+
+// aiderTarget owns .aider.conf.yml outright: it never reads the file's
+// prior contents, it just regenerates the whole thing.
+type aiderTarget struct{}
+
+func (aiderTarget) Name() string { return "aider" }
+
+func (aiderTarget) OwnsFile() bool { return true }
+
+func (aiderTarget) Build(cfg config) (any, error) {
+	return buildAiderConfig(cfg), nil
+}
+
+func (aiderTarget) Render(v any) ([]byte, error) {
+	return renderAiderConfig(v.(aiderConfig))
+}
+
+func (aiderTarget) Patch(existing, rendered []byte) ([]byte, error) {
+	return rendered, nil
+}
+
+func buildAiderConfig(cfg config) aiderConfig {
+	return aiderConfig{
+		Allow:    ensureSlice(normalizeList(cfg.Bash.Allow, true)),
+		Disallow: ensureSlice(mergeUnique(normalizeList(cfg.Bash.Ask, true), normalizeList(cfg.Bash.Deny, true))),
+	}
+}
+
+func renderAiderConfig(ac aiderConfig) ([]byte, error) {
+	data, err := yaml.Marshal(ac)
+	if err != nil {
+		return nil, fmt.Errorf("marshal aider config: %w", err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# .aider.conf.yml\n")
+	builder.WriteString("# Generated by tools/permissions-gen. Do not edit by hand.\n\n")
+	builder.Write(data)
+	return []byte(builder.String()), nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import "strings"
+
+// bashenvVars is the shell-guardrail view of the bash allow/ask/deny lists:
+// one pipe-separated "prefix:*" glob per tier, suitable for sourcing from a
+// pre-commit hook or a shell's PROMPT_COMMAND.
+type bashenvVars struct {
+	Allowed []string
+	Ask     []string
+	Denied  []string
+}
+
+// bashenvTarget owns its output file outright: it's a plain shell script,
+// regenerated in full each run rather than patched.
+type bashenvTarget struct{}
+
+func (bashenvTarget) Name() string { return "bashenv" }
+
+func (bashenvTarget) OwnsFile() bool { return true }
+
+func (bashenvTarget) Build(cfg config) (any, error) {
+	return buildBashenvVars(cfg), nil
+}
+
+func (bashenvTarget) Render(v any) ([]byte, error) {
+	return renderBashenvVars(v.(bashenvVars)), nil
+}
+
+func (bashenvTarget) Patch(existing, rendered []byte) ([]byte, error) {
+	return rendered, nil
+}
+
+func buildBashenvVars(cfg config) bashenvVars {
+	return bashenvVars{
+		Allowed: ensureSlice(normalizeList(cfg.Bash.Allow, true)),
+		Ask:     ensureSlice(normalizeList(cfg.Bash.Ask, true)),
+		Denied:  ensureSlice(normalizeList(cfg.Bash.Deny, true)),
+	}
+}
+
+func renderBashenvVars(v bashenvVars) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Generated by tools/permissions-gen. Do not edit by hand.\n\n")
+	writeBashenvExport(&b, "ALLOWED_BASH_PREFIXES", v.Allowed)
+	writeBashenvExport(&b, "ASK_BASH_PREFIXES", v.Ask)
+	writeBashenvExport(&b, "DENIED_BASH_PREFIXES", v.Denied)
+	return []byte(b.String())
+}
+
+func writeBashenvExport(b *strings.Builder, name string, prefixes []string) {
+	patterns := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		patterns[i] = p + ":*"
+	}
+	b.WriteString("export " + name + "=" + shellSingleQuote(strings.Join(patterns, "|")) + "\n")
+}
+
+// shellSingleQuote wraps s in POSIX shell single quotes so it's always
+// sourced as a literal value, with no command substitution, variable
+// expansion, or quote-breaking possible regardless of what a permissions
+// entry contains.
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
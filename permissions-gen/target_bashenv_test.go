@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestRenderBashenvVars(t *testing.T) {
+	got := string(renderBashenvVars(bashenvVars{
+		Allowed: []string{"git status", "ls"},
+		Ask:     []string{"cp"},
+		Denied:  []string{"rm"},
+	}))
+
+	want := "#!/bin/sh\n" +
+		"# Generated by tools/permissions-gen. Do not edit by hand.\n\n" +
+		"export ALLOWED_BASH_PREFIXES='git status:*|ls:*'\n" +
+		"export ASK_BASH_PREFIXES='cp:*'\n" +
+		"export DENIED_BASH_PREFIXES='rm:*'\n"
+	if got != want {
+		t.Fatalf("renderBashenvVars() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderBashenvVars_EscapesShellMetacharacters(t *testing.T) {
+	got := string(renderBashenvVars(bashenvVars{
+		Allowed: []string{`git status"; touch /tmp/pwned; echo "`},
+	}))
+
+	want := "#!/bin/sh\n" +
+		"# Generated by tools/permissions-gen. Do not edit by hand.\n\n" +
+		`export ALLOWED_BASH_PREFIXES='git status"; touch /tmp/pwned; echo ":*'` + "\n" +
+		"export ASK_BASH_PREFIXES=''\n" +
+		"export DENIED_BASH_PREFIXES=''\n"
+	if got != want {
+		t.Fatalf("renderBashenvVars() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/waki285/dotfiles-tools/permissions-gen/jsonedit"
+)
+
+// claudePermissions is the `permissions` object written into Claude's
+// settings.json(.tmpl).
+type claudePermissions struct {
+	Allow                 []string `json:"allow"`
+	Ask                   []string `json:"ask"`
+	Deny                  []string `json:"deny"`
+	AdditionalDirectories []string `json:"additionalDirectories"`
+}
+
+// claudeTarget patches a `permissions` block into an existing
+// settings.json(.tmpl), either between PERMISSIONS markers or, failing
+// that, as the "permissions" key of the JSON document itself.
+type claudeTarget struct{}
+
+func (claudeTarget) Name() string { return "claude" }
+
+func (claudeTarget) Build(cfg config) (any, error) {
+	return buildClaudePermissions(cfg), nil
+}
+
+func (claudeTarget) Render(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v.(claudePermissions), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal permissions: %w", err)
+	}
+	return data, nil
+}
+
+func (claudeTarget) Patch(existing, rendered []byte) ([]byte, error) {
+	out, err := replacePermissionsBlock(string(existing), rendered)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+func buildClaudePermissions(cfg config) claudePermissions {
+	allow := expandWithBash(cfg.Claude.Allow, cfg.Bash.Allow)
+	ask := expandWithBash(cfg.Claude.Ask, cfg.Bash.Ask)
+	deny := expandWithBash(cfg.Claude.Deny, cfg.Bash.Deny)
+
+	return claudePermissions{
+		Allow:                 allow,
+		Ask:                   ensureSlice(ask),
+		Deny:                  ensureSlice(deny),
+		AdditionalDirectories: ensureSlice(normalizeList(cfg.Claude.AdditionalDirectories, false)),
+	}
+}
+
+func replacePermissionsBlock(contents string, rendered []byte) (string, error) {
+	start := strings.Index(contents, startMarker)
+	end := strings.Index(contents, endMarker)
+
+	if start != -1 && end != -1 && start < end {
+		lines, err := innerJSONLines(string(rendered))
+		if err != nil {
+			return "", err
+		}
+		return replaceBlockWithLines(contents, start, end, lines)
+	}
+
+	return replacePermissionsJSON(contents, rendered)
+}
+
+func replacePermissionsJSON(contents string, rendered []byte) (string, error) {
+	doc, err := jsonedit.Parse([]byte(contents))
+	if err != nil {
+		return "", fmt.Errorf("parse json: %w", err)
+	}
+	node, ok := doc.Find("permissions")
+	if !ok {
+		return "", fmt.Errorf("permissions object not found")
+	}
+	if node.Kind != jsonedit.KindObject {
+		return "", fmt.Errorf("permissions value must be object")
+	}
+
+	indent := lineIndentForPos(contents, node.KeyStart)
+	replacement := indentMultilineValue(string(rendered), indent)
+	node.Replace([]byte(replacement))
+
+	out, err := doc.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReplacePermissionsBlock(t *testing.T) {
+	input := strings.Join([]string{
+		"before",
+		"  " + startMarker,
+		"  \"old\": true",
+		"  " + endMarker,
+		"after",
+		"",
+	}, "\n")
+	rendered, err := json.MarshalIndent(claudePermissions{
+		Allow:                 []string{"a"},
+		Ask:                   []string{},
+		Deny:                  []string{},
+		AdditionalDirectories: []string{},
+	}, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent() error = %v", err)
+	}
+
+	got, err := replacePermissionsBlock(input, rendered)
+	if err != nil {
+		t.Fatalf("replacePermissionsBlock() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"before",
+		"  " + startMarker,
+		"  \"allow\": [",
+		"    \"a\"",
+		"  ],",
+		"  \"ask\": [],",
+		"  \"deny\": [],",
+		"  \"additionalDirectories\": []",
+		"  " + endMarker,
+		"after",
+		"",
+	}, "\n")
+
+	if got != want {
+		t.Fatalf("replacePermissionsBlock() output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestReplacePermissionsBlock_MissingMarkers(t *testing.T) {
+	_, err := replacePermissionsBlock("no markers here", []byte("{}"))
+	if err == nil {
+		t.Fatal("replacePermissionsBlock() expected error for missing markers")
+	}
+}
+
+func TestClaudeTarget_Patch_JSONFallback(t *testing.T) {
+	input := `{"permissions": {"old": true}}`
+	rendered, err := json.MarshalIndent(claudePermissions{Allow: []string{"a"}}, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent() error = %v", err)
+	}
+
+	got, err := (claudeTarget{}).Patch([]byte(input), rendered)
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if !strings.Contains(string(got), `"allow": [`) {
+		t.Fatalf("Patch() = %s, want it to contain the rendered permissions", got)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type codexRule struct {
+	PatternPrefix []string
+	PatternAlts   []string
+	Decision      string
+	Match         string
+}
+
+// codexTarget owns ~/.codex/rules/default.rules outright: it never reads
+// the file's prior contents, it just regenerates the whole thing.
+type codexTarget struct{}
+
+func (codexTarget) Name() string { return "codex" }
+
+func (codexTarget) OwnsFile() bool { return true }
+
+func (codexTarget) Build(cfg config) (any, error) {
+	return buildCodexRules(cfg), nil
+}
+
+func (codexTarget) Render(v any) ([]byte, error) {
+	return []byte(renderCodexRules(v.([]codexRule))), nil
+}
+
+func (codexTarget) Patch(existing, rendered []byte) ([]byte, error) {
+	return rendered, nil
+}
+
+func buildCodexRules(cfg config) []codexRule {
+	var rules []codexRule
+	rules = append(rules, buildCodexDecisionRules("allow", cfg.Bash.Allow)...)
+	rules = append(rules, buildCodexDecisionRules("prompt", cfg.Bash.Ask)...)
+	rules = append(rules, buildCodexDecisionRules("forbidden", cfg.Bash.Deny)...)
+	return rules
+}
+
+type commandGroup struct {
+	prefix []string
+	alts   []string
+	seen   map[string]struct{}
+}
+
+type groupedCommands struct {
+	order   []string
+	groups  map[string]*commandGroup
+	singles map[string][]string
+}
+
+func groupCommands(commands []string) groupedCommands {
+	gc := groupedCommands{
+		groups:  make(map[string]*commandGroup),
+		singles: make(map[string][]string),
+	}
+
+	for _, cmd := range commands {
+		tokens := strings.Fields(cmd)
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) == 1 {
+			key := "single|" + tokens[0]
+			if _, ok := gc.singles[key]; !ok {
+				gc.singles[key] = tokens
+				gc.order = append(gc.order, key)
+			}
+			continue
+		}
+
+		prefix := strings.Join(tokens[:len(tokens)-1], "\x1f")
+		key := fmt.Sprintf("group|%d|%s", len(tokens), prefix)
+		if _, ok := gc.groups[key]; !ok {
+			gc.groups[key] = &commandGroup{
+				prefix: tokens[:len(tokens)-1],
+				alts:   []string{},
+				seen:   make(map[string]struct{}),
+			}
+			gc.order = append(gc.order, key)
+		}
+
+		last := tokens[len(tokens)-1]
+		if _, ok := gc.groups[key].seen[last]; ok {
+			continue
+		}
+		gc.groups[key].seen[last] = struct{}{}
+		gc.groups[key].alts = append(gc.groups[key].alts, last)
+	}
+
+	return gc
+}
+
+func buildCodexDecisionRules(decision string, commands []string) []codexRule {
+	gc := groupCommands(commands)
+
+	var rules []codexRule
+	for _, key := range gc.order {
+		if tokens, ok := gc.singles[key]; ok {
+			rules = append(rules, codexRule{
+				PatternPrefix: tokens,
+				Decision:      decision,
+				Match:         strings.Join(tokens, " "),
+			})
+			continue
+		}
+		group := gc.groups[key]
+		if group == nil {
+			continue
+		}
+		if len(group.alts) == 1 {
+			full := append([]string{}, group.prefix...)
+			full = append(full, group.alts[0])
+			rules = append(rules, codexRule{
+				PatternPrefix: full,
+				Decision:      decision,
+				Match:         strings.Join(full, " "),
+			})
+			continue
+		}
+		matchTokens := append([]string{}, group.prefix...)
+		matchTokens = append(matchTokens, group.alts[0])
+		rules = append(rules, codexRule{
+			PatternPrefix: group.prefix,
+			PatternAlts:   group.alts,
+			Decision:      decision,
+			Match:         strings.Join(matchTokens, " "),
+		})
+	}
+
+	return rules
+}
+
+func renderCodexRules(rules []codexRule) string {
+	var builder strings.Builder
+	builder.WriteString("# ~/.codex/rules/default.rules\n")
+	builder.WriteString("# Generated by tools/permissions-gen. Do not edit by hand.\n\n")
+	for i, rule := range rules {
+		builder.WriteString("prefix_rule(\n")
+		builder.WriteString(renderCodexPattern(rule))
+		builder.WriteString(renderCodexDecision(rule.Decision))
+		builder.WriteString(renderCodexMatch(rule.Match))
+		builder.WriteString(")\n")
+		if i < len(rules)-1 {
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+func renderCodexPattern(rule codexRule) string {
+	if len(rule.PatternAlts) == 0 {
+		return fmt.Sprintf("  pattern = [%s],\n", joinQuoted(rule.PatternPrefix))
+	}
+	var builder strings.Builder
+	builder.WriteString("  pattern = [")
+	builder.WriteString(joinQuoted(rule.PatternPrefix))
+	builder.WriteString(", [\n")
+	for _, alt := range rule.PatternAlts {
+		fmt.Fprintf(&builder, "    %q,\n", alt)
+	}
+	builder.WriteString("  ]],\n")
+	return builder.String()
+}
+
+func renderCodexDecision(decision string) string {
+	if decision == "" || decision == "allow" {
+		return "  decision = \"allow\",\n"
+	}
+	return fmt.Sprintf("  decision = %q,\n", decision)
+}
+
+func renderCodexMatch(match string) string {
+	if strings.TrimSpace(match) == "" {
+		return ""
+	}
+	return fmt.Sprintf("  match = [%q],\n", match)
+}
+
+func joinQuoted(tokens []string) string {
+	parts := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		parts = append(parts, fmt.Sprintf("%q", token))
+	}
+	return strings.Join(parts, ", ")
+}
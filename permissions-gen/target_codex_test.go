@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestRenderCodexPattern_NoAlts(t *testing.T) {
+	got := renderCodexPattern(codexRule{PatternPrefix: []string{"git", "status"}})
+	want := "  pattern = [\"git\", \"status\"],\n"
+	if got != want {
+		t.Fatalf("renderCodexPattern() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderCodexPattern_WithAlts(t *testing.T) {
+	got := renderCodexPattern(codexRule{PatternPrefix: []string{"git"}, PatternAlts: []string{"status", "log"}})
+	want := "  pattern = [\"git\", [\n    \"status\",\n    \"log\",\n  ]],\n"
+	if got != want {
+		t.Fatalf("renderCodexPattern() = %q, want %q", got, want)
+	}
+}
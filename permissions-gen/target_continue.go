@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/waki285/dotfiles-tools/permissions-gen/jsonedit"
+)
+
+// continueTarget patches the "allowedCommands" array of an existing
+// Continue config.json, leaving the rest of the document (models,
+// slashCommands, etc.) untouched. Continue's allowedCommands is an
+// allow-list only, so buildContinueAllowedCommands draws from
+// cfg.Bash.Allow alone: anything not listed simply falls back to
+// Continue's own confirmation prompt, which already covers the "ask" and
+// "deny" cases.
+type continueTarget struct{}
+
+func (continueTarget) Name() string { return "continue" }
+
+func (continueTarget) Build(cfg config) (any, error) {
+	return buildContinueAllowedCommands(cfg), nil
+}
+
+func (continueTarget) Render(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v.([]string), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal allowedCommands: %w", err)
+	}
+	return data, nil
+}
+
+func (continueTarget) Patch(existing, rendered []byte) ([]byte, error) {
+	doc, err := jsonedit.Parse(existing)
+	if err != nil {
+		return nil, fmt.Errorf("parse json: %w", err)
+	}
+	node, ok := doc.Find("allowedCommands")
+	if !ok {
+		return nil, fmt.Errorf("allowedCommands array not found")
+	}
+	if node.Kind != jsonedit.KindArray {
+		return nil, fmt.Errorf("allowedCommands value must be array")
+	}
+
+	indent := lineIndentForPos(string(existing), node.KeyStart)
+	replacement := indentMultilineValue(string(rendered), indent)
+	node.Replace([]byte(replacement))
+
+	return doc.Bytes()
+}
+
+func buildContinueAllowedCommands(cfg config) []string {
+	return ensureSlice(normalizeList(cfg.Bash.Allow, true))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestContinueTarget_Patch(t *testing.T) {
+	input := strings.Join([]string{
+		"{",
+		"  \"models\": [],",
+		"  \"allowedCommands\": [",
+		"    \"old\"",
+		"  ]",
+		"}",
+		"",
+	}, "\n")
+
+	rendered, err := (continueTarget{}).Render([]string{"git", "ls"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	got, err := (continueTarget{}).Patch([]byte(input), rendered)
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"{",
+		"  \"models\": [],",
+		"  \"allowedCommands\": [",
+		"    \"git\",",
+		"    \"ls\"",
+		"  ]",
+		"}",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Fatalf("Patch() output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestContinueTarget_Patch_CompactInput(t *testing.T) {
+	rendered, err := (continueTarget{}).Render([]string{"git"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	got, err := (continueTarget{}).Patch([]byte(`{"allowedCommands":["old"]}`), rendered)
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if !json.Valid(got) {
+		t.Fatalf("Patch() produced invalid JSON on a compact input: %s", got)
+	}
+}
+
+func TestContinueTarget_Patch_MissingField(t *testing.T) {
+	_, err := (continueTarget{}).Patch([]byte("{}"), []byte("[]"))
+	if err == nil {
+		t.Fatal("Patch() expected error for missing allowedCommands array")
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/waki285/dotfiles-tools/permissions-gen/jsonedit"
+)
+
+type opencodeRule struct {
+	Pattern  string
+	Decision string
+}
+
+type opencodeSection struct {
+	Name     string
+	Rules    []opencodeRule
+	Scalar   string
+	IsScalar bool
+}
+
+// opencodeTarget patches a `permission` block into an existing
+// opencode.json, either between PERMISSIONS markers or, failing that, as
+// the "permission" key of the JSON document itself.
+type opencodeTarget struct{}
+
+func (opencodeTarget) Name() string { return "opencode" }
+
+func (opencodeTarget) Build(cfg config) (any, error) {
+	return buildOpencodeSections(cfg), nil
+}
+
+func (opencodeTarget) Render(v any) ([]byte, error) {
+	return []byte(renderOpencodePermissionsJSON(v.([]opencodeSection))), nil
+}
+
+func (opencodeTarget) Patch(existing, rendered []byte) ([]byte, error) {
+	lines, err := innerJSONLines(string(rendered))
+	if err != nil {
+		return nil, err
+	}
+	out, err := replaceOpencodePermissions(string(existing), string(rendered), lines)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+func buildOpencodeSections(cfg config) []opencodeSection {
+	var sections []opencodeSection
+	if cfg.Opencode.Bash.IsScalar {
+		sections = append(sections, opencodeSection{
+			Name:     "bash",
+			Scalar:   cfg.Opencode.Bash.Scalar,
+			IsScalar: true,
+		})
+	} else {
+		sections = append(sections, opencodeSection{
+			Name:  "bash",
+			Rules: buildOpencodeBashRules(cfg),
+		})
+	}
+
+	if len(cfg.Opencode.Others) == 0 {
+		return sections
+	}
+
+	var names []string
+	for name := range cfg.Opencode.Others {
+		if name == "bash" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section := cfg.Opencode.Others[name]
+		if section.IsScalar {
+			sections = append(sections, opencodeSection{
+				Name:     name,
+				Scalar:   section.Scalar,
+				IsScalar: true,
+			})
+			continue
+		}
+		rules := buildOpencodeSectionRules(section)
+		sections = append(sections, opencodeSection{Name: name, Rules: rules})
+	}
+	return sections
+}
+
+func buildOpencodeBashRules(cfg config) []opencodeRule {
+	return buildOpencodeRulesForSection(
+		cfg.Opencode.Bash.Default,
+		append(cfg.Bash.Allow, cfg.Opencode.Bash.Allow...),
+		append(cfg.Bash.Ask, cfg.Opencode.Bash.Ask...),
+		append(cfg.Bash.Deny, cfg.Opencode.Bash.Deny...),
+		true,
+	)
+}
+
+func buildOpencodeSectionRules(section opencodeSectionConfig) []opencodeRule {
+	return buildOpencodeRulesForSection(
+		section.Default,
+		section.Allow,
+		section.Ask,
+		section.Deny,
+		false,
+	)
+}
+
+func buildOpencodeRulesForSection(defaultDecision string, allow, ask, deny []string, expand bool) []opencodeRule {
+	decision := strings.TrimSpace(defaultDecision)
+	if decision == "" {
+		decision = "allow"
+	}
+
+	rules := []opencodeRule{{Pattern: "*", Decision: decision}}
+	rules = append(rules, buildOpencodeDecisionRules("allow", allow, expand)...)
+	rules = append(rules, buildOpencodeDecisionRules("ask", ask, expand)...)
+	rules = append(rules, buildOpencodeDecisionRules("deny", deny, expand)...)
+	return rules
+}
+
+func buildOpencodeDecisionRules(decision string, values []string, expand bool) []opencodeRule {
+	var patterns []string
+	if expand {
+		patterns = expandOpencodePatterns(values)
+	} else {
+		patterns = normalizeList(values, true)
+	}
+	rules := make([]opencodeRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		rules = append(rules, opencodeRule{
+			Pattern:  pattern,
+			Decision: decision,
+		})
+	}
+	return rules
+}
+
+func expandOpencodePatterns(values []string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, value := range values {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" {
+			continue
+		}
+		out, seen = appendUnique(out, seen, trimmed)
+		if !containsWildcard(trimmed) {
+			out, seen = appendUnique(out, seen, trimmed+" *")
+		}
+	}
+	return out
+}
+
+func containsWildcard(value string) bool {
+	return strings.ContainsAny(value, "*?")
+}
+
+func renderOpencodeSectionJSON(rules []opencodeRule) string {
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for i, rule := range rules {
+		builder.WriteString("  ")
+		builder.WriteString(jsonString(rule.Pattern))
+		builder.WriteString(": ")
+		builder.WriteString(jsonString(rule.Decision))
+		if i < len(rules)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
+
+func renderOpencodePermissionsJSON(sections []opencodeSection) string {
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for i, section := range sections {
+		builder.WriteString("  ")
+		builder.WriteString(jsonString(section.Name))
+		builder.WriteString(": ")
+		if section.IsScalar {
+			builder.WriteString(jsonString(section.Scalar))
+		} else {
+			builder.WriteString(indentMultilineValue(renderOpencodeSectionJSON(section.Rules), "  "))
+		}
+		if i < len(sections)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
+
+func opencodePermissionsLinesFromJSON(permissionsJSON string) ([]string, error) {
+	return innerJSONLines(permissionsJSON)
+}
+
+func replaceOpencodePermissions(contents, permissionsJSON string, lines []string) (string, error) {
+	start := strings.Index(contents, startMarker)
+	end := strings.Index(contents, endMarker)
+	if start == -1 || end == -1 || start >= end {
+		return replaceOpencodePermissionsJSON(contents, permissionsJSON)
+	}
+	return replaceBlockWithLines(contents, start, end, lines)
+}
+
+func replaceOpencodePermissionsJSON(contents, permissionsJSON string) (string, error) {
+	doc, err := jsonedit.Parse([]byte(contents))
+	if err != nil {
+		return "", fmt.Errorf("parse json: %w", err)
+	}
+	node, ok := doc.Find("permission")
+	if !ok {
+		return "", fmt.Errorf("permission object not found")
+	}
+	if node.Kind != jsonedit.KindObject {
+		return "", fmt.Errorf("permission value must be object")
+	}
+
+	indent := lineIndentForPos(contents, node.KeyStart)
+	replacement := indentMultilineValue(permissionsJSON, indent)
+	node.Replace([]byte(replacement))
+
+	out, err := doc.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
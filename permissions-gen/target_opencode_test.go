@@ -0,0 +1,162 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandOpencodePatterns(t *testing.T) {
+	got := expandOpencodePatterns([]string{"git", "git", "rm *", "ls?", " "})
+	want := []string{"git", "git *", "rm *", "ls?"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expandOpencodePatterns() = %#v, want %#v", got, want)
+	}
+}
+
+func TestReplaceOpencodePermissions_WithMarkers(t *testing.T) {
+	input := strings.Join([]string{
+		"{",
+		"  \"permission\": {",
+		"    " + startMarker,
+		"    \"old\": \"value\"",
+		"    " + endMarker,
+		"  }",
+		"}",
+		"",
+	}, "\n")
+	sections := []opencodeSection{
+		{
+			Name: "bash",
+			Rules: []opencodeRule{
+				{Pattern: "*", Decision: "ask"},
+			},
+		},
+		{
+			Name: "webfetch",
+			Rules: []opencodeRule{
+				{Pattern: "*", Decision: "allow"},
+			},
+		},
+	}
+	permissionsJSON := renderOpencodePermissionsJSON(sections)
+	lines, err := opencodePermissionsLinesFromJSON(permissionsJSON)
+	if err != nil {
+		t.Fatalf("opencodePermissionsLinesFromJSON() error = %v", err)
+	}
+
+	got, err := replaceOpencodePermissions(input, permissionsJSON, lines)
+	if err != nil {
+		t.Fatalf("replaceOpencodePermissions() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"{",
+		"  \"permission\": {",
+		"    " + startMarker,
+		"    \"bash\": {",
+		"      \"*\": \"ask\"",
+		"    },",
+		"    \"webfetch\": {",
+		"      \"*\": \"allow\"",
+		"    }",
+		"    " + endMarker,
+		"  }",
+		"}",
+		"",
+	}, "\n")
+	if got != want {
+		t.Fatalf("replaceOpencodePermissions() output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestReplaceOpencodePermissions_FallbackJSON(t *testing.T) {
+	input := strings.Join([]string{
+		"{",
+		"  \"permission\": {",
+		"    \"bash\": {",
+		"      \"old\": \"value\"",
+		"    },",
+		"    \"other\": 1",
+		"  }",
+		"}",
+		"",
+	}, "\n")
+	permissionsJSON := strings.Join([]string{
+		"{",
+		"  \"bash\": {",
+		"    \"*\": \"ask\"",
+		"  },",
+		"  \"webfetch\": {",
+		"    \"*\": \"allow\"",
+		"  }",
+		"}",
+	}, "\n")
+	lines, err := opencodePermissionsLinesFromJSON(permissionsJSON)
+	if err != nil {
+		t.Fatalf("opencodePermissionsLinesFromJSON() error = %v", err)
+	}
+
+	got, err := replaceOpencodePermissions(input, permissionsJSON, lines)
+	if err != nil {
+		t.Fatalf("replaceOpencodePermissions() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"{",
+		"  \"permission\": {",
+		"    \"bash\": {",
+		"      \"*\": \"ask\"",
+		"    },",
+		"    \"webfetch\": {",
+		"      \"*\": \"allow\"",
+		"    }",
+		"  }",
+		"}",
+		"",
+	}, "\n")
+	if got != want {
+		t.Fatalf("replaceOpencodePermissions() output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestRenderOpencodePermissionsJSON_ScalarSection(t *testing.T) {
+	sections := []opencodeSection{
+		{
+			Name: "bash",
+			Rules: []opencodeRule{
+				{Pattern: "*", Decision: "ask"},
+			},
+		},
+		{
+			Name:     "webfetch",
+			Scalar:   "allow",
+			IsScalar: true,
+		},
+	}
+
+	got := renderOpencodePermissionsJSON(sections)
+	want := strings.Join([]string{
+		"{",
+		"  \"bash\": {",
+		"    \"*\": \"ask\"",
+		"  },",
+		"  \"webfetch\": \"allow\"",
+		"}",
+	}, "\n")
+	if got != want {
+		t.Fatalf("renderOpencodePermissionsJSON() = %q, want %q", got, want)
+	}
+}
+
+func TestReplaceOpencodePermissions_MissingPermission(t *testing.T) {
+	permissionsJSON := "{\n  \"bash\": {}\n}"
+	lines, err := opencodePermissionsLinesFromJSON(permissionsJSON)
+	if err != nil {
+		t.Fatalf("opencodePermissionsLinesFromJSON() error = %v", err)
+	}
+	_, err = replaceOpencodePermissions("{}", permissionsJSON, lines)
+	if err == nil {
+		t.Fatal("replaceOpencodePermissions() expected error for missing permission object")
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import "sort"
+
+// Target is one AI-assistant integration the generator can produce: it
+// builds a tool-specific value from the shared config, renders that value
+// to bytes, and patches those bytes into an existing file (a no-op full
+// overwrite for tools, like Codex, that own their file outright).
+type Target interface {
+	Name() string
+	Build(cfg config) (any, error)
+	Render(v any) ([]byte, error)
+	Patch(existing, rendered []byte) ([]byte, error)
+}
+
+// fileOwnerTarget is an optional capability a Target implements when it
+// generates its output file's entire contents rather than patching a block
+// into one the user otherwise owns (Codex, Aider). writeTarget then only
+// requires the containing directory to exist and never conditions Patch on
+// prior file contents.
+type fileOwnerTarget interface {
+	Target
+	OwnsFile() bool
+}
+
+var targetRegistry = map[string]Target{}
+
+func registerTarget(t Target) {
+	targetRegistry[t.Name()] = t
+}
+
+func targetNames() []string {
+	names := make([]string, 0, len(targetRegistry))
+	for name := range targetRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	registerTarget(claudeTarget{})
+	registerTarget(codexTarget{})
+	registerTarget(opencodeTarget{})
+	registerTarget(aiderTarget{})
+	registerTarget(continueTarget{})
+	registerTarget(bashenvTarget{})
+}
+
+// runTarget builds and renders t's value for cfg, the two steps every
+// target goes through before Patch merges the result into an existing
+// file.
+func runTarget(t Target, cfg config) ([]byte, error) {
+	v, err := t.Build(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return t.Render(v)
+}
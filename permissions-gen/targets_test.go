@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTargetNames(t *testing.T) {
+	got := targetNames()
+	want := []string{"aider", "bashenv", "claude", "codex", "continue", "opencode"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("targetNames() = %#v, want %#v", got, want)
+	}
+}
+
+// TestTargetBuild exercises each registered Target's Build method against a
+// scenario matched to its config shape, keyed off the registry rather than
+// calling each target's build function directly. Adding a new target means
+// adding a case here, not a new test function.
+func TestTargetBuild(t *testing.T) {
+	cases := []struct {
+		target string
+		cfg    config
+		want   any
+	}{
+		{
+			target: "claude",
+			cfg: config{
+				Bash: bashConfig{Allow: []string{"git"}, Ask: []string{"cp"}, Deny: []string{"rm"}},
+				Claude: claudeConfig{
+					Allow:                 []string{"foo", bashSentinel},
+					Ask:                   nil,
+					Deny:                  []string{"   "},
+					AdditionalDirectories: []string{" /tmp ", "", " /var "},
+				},
+			},
+			want: claudePermissions{
+				Allow:                 []string{"foo", "Bash(git:*)"},
+				Ask:                   []string{"Bash(cp:*)"},
+				Deny:                  []string{"Bash(rm:*)"},
+				AdditionalDirectories: []string{"/tmp", "/var"},
+			},
+		},
+		{
+			target: "codex",
+			cfg: config{
+				Bash: bashConfig{Allow: []string{"git status", "git log", "ls", "git status"}},
+			},
+			want: []codexRule{
+				{PatternPrefix: []string{"git"}, PatternAlts: []string{"status", "log"}, Decision: "allow", Match: "git status"},
+				{PatternPrefix: []string{"ls"}, Decision: "allow", Match: "ls"},
+			},
+		},
+		{
+			target: "opencode",
+			cfg: config{
+				Bash: bashConfig{Allow: []string{"foo", "foo", "bar *"}},
+				Opencode: opencodeConfig{
+					Others: map[string]opencodeSectionConfig{
+						"webfetch": {Allow: []string{"a", "a", "b"}},
+					},
+				},
+			},
+			want: []opencodeSection{
+				{
+					Name: "bash",
+					Rules: []opencodeRule{
+						{Pattern: "*", Decision: "allow"},
+						{Pattern: "foo", Decision: "allow"},
+						{Pattern: "foo *", Decision: "allow"},
+						{Pattern: "bar *", Decision: "allow"},
+					},
+				},
+				{
+					Name: "webfetch",
+					Rules: []opencodeRule{
+						{Pattern: "*", Decision: "allow"},
+						{Pattern: "a", Decision: "allow"},
+						{Pattern: "b", Decision: "allow"},
+					},
+				},
+			},
+		},
+		{
+			target: "aider",
+			cfg: config{
+				Bash: bashConfig{Allow: []string{"git"}, Ask: []string{"cp"}, Deny: []string{"rm"}},
+			},
+			want: aiderConfig{
+				Allow:    []string{"git"},
+				Disallow: []string{"cp", "rm"},
+			},
+		},
+		{
+			target: "continue",
+			cfg: config{
+				Bash: bashConfig{Allow: []string{"git", "git"}, Ask: []string{"cp"}, Deny: []string{"rm"}},
+			},
+			want: []string{"git"},
+		},
+		{
+			target: "bashenv",
+			cfg: config{
+				Bash: bashConfig{Allow: []string{"git", "git"}, Ask: []string{"cp"}, Deny: []string{"rm"}},
+			},
+			want: bashenvVars{
+				Allowed: []string{"git"},
+				Ask:     []string{"cp"},
+				Denied:  []string{"rm"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.target, func(t *testing.T) {
+			target, ok := targetRegistry[tc.target]
+			if !ok {
+				t.Fatalf("target %q not registered", tc.target)
+			}
+			got, err := target.Build(tc.cfg)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Build() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
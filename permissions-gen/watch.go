@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchDebounce = 100 * time.Millisecond
+
+type watchSnapshot struct {
+	allow, ask, deny int
+}
+
+func snapshotPerm(perm claudePermissions) watchSnapshot {
+	return watchSnapshot{allow: len(perm.Allow), ask: len(perm.Ask), deny: len(perm.Deny)}
+}
+
+func logSnapshotDiff(prev, next watchSnapshot, changed []string) {
+	if len(changed) == 0 {
+		fmt.Println("regenerated: no target files changed")
+		return
+	}
+	fmt.Printf("regenerated %s: claude allow %+d, ask %+d, deny %+d\n",
+		strings.Join(changed, ", "), next.allow-prev.allow, next.ask-prev.ask, next.deny-prev.deny)
+}
+
+// runWatch runs the generator once, then keeps re-running it whenever
+// dataPath or any policy file it references changes, until the process is
+// interrupted. Bursts of writes (e.g. an editor's atomic save) are
+// coalesced behind watchDebounce. A failed regeneration leaves the
+// previously written files untouched and is logged, not fatal.
+func runWatch(e env, dataPath string, paths targetPaths, selected []string, profile string) error {
+	if profile == "all" {
+		return fmt.Errorf("-watch does not support -profile all; pick a single profile")
+	}
+
+	dataPath, paths, root, err := resolvePaths(e, dataPath, paths)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(e.fs, dataPath)
+	if err != nil {
+		return err
+	}
+	if _, err := runAndReport(e, dataPath, paths, selected, profile); err != nil {
+		return err
+	}
+	prev := snapshotPerm(buildClaudePermissions(cfg))
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	watchFiles := []string{dataPath}
+	for _, p := range cfg.Policies {
+		resolved, err := resolveRelative(e, root, p)
+		if err != nil {
+			return fmt.Errorf("resolve policy %s: %w", p, err)
+		}
+		watchFiles = append(watchFiles, resolved)
+	}
+	names := make(map[string]struct{}, len(watchFiles))
+	for _, f := range watchFiles {
+		names[filepath.Clean(f)] = struct{}{}
+	}
+	// Watch containing directories, not the files themselves: editors like
+	// vim save atomically (write temp file, rename over the original),
+	// which would otherwise orphan a watch on the file's old inode.
+	for _, dir := range watchDirs(watchFiles) {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watch %s: %w", dir, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "watching %s for changes (ctrl-c to stop)\n", dataPath)
+
+	var timer *time.Timer
+	fire := make(chan struct{}, 1)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if _, ok := names[filepath.Clean(event.Name)]; !ok {
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(watchDebounce, func() {
+				select {
+				case fire <- struct{}{}:
+				default:
+				}
+			})
+		case watchErr, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(os.Stderr, "watch error:", watchErr)
+		case <-fire:
+			newCfg, err := loadConfig(e.fs, dataPath)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "regeneration failed, keeping last-good output:", err)
+				continue
+			}
+			changed, err := runAndReport(e, dataPath, paths, selected, profile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "regeneration failed, keeping last-good output:", err)
+				continue
+			}
+			next := snapshotPerm(buildClaudePermissions(newCfg))
+			logSnapshotDiff(prev, next, changed)
+			prev, cfg = next, newCfg
+		}
+	}
+}
+
+func watchDirs(files []string) []string {
+	seen := make(map[string]struct{})
+	var dirs []string
+	for _, f := range files {
+		dir := filepath.Dir(f)
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+		seen[dir] = struct{}{}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWatchDirs(t *testing.T) {
+	got := watchDirs([]string{"/a/b/permissions.yaml", "/a/b/policy.rego", "/c/other.rego"})
+	want := []string{"/a/b", "/c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("watchDirs() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSnapshotPerm(t *testing.T) {
+	got := snapshotPerm(claudePermissions{
+		Allow: []string{"a", "b"},
+		Ask:   []string{"c"},
+		Deny:  []string{},
+	})
+	want := watchSnapshot{allow: 2, ask: 1, deny: 0}
+	if got != want {
+		t.Fatalf("snapshotPerm() = %#v, want %#v", got, want)
+	}
+}